@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// TypeCoverage lists the fields of a raw schema object type that are
+// referenced by UI operations, along with the source files referencing them
+// and the hooks and pages using those operations.
+type TypeCoverage struct {
+	Name  string `json:"name"`
+	Total int    `json:"total"`
+	// Fields maps a referenced field name to the sorted source paths using it
+	Fields map[string][]string `json:"fields"`
+	// Hooks and Pages map a referenced field name to the sorted hook and page
+	// files using it, resolved as who-uses does
+	Hooks map[string][]string `json:"hooks"`
+	Pages map[string][]string `json:"pages"`
+}
+
+// fieldUse collects the files using a field, as sets.
+type fieldUse struct {
+	sources, hooks, pages map[string]bool
+}
+
+// ComputeCoverage runs every operation of the sources below sourcePath
+// through processField, fragments of other documents resolved, and merges
+// the resulting visited maps. Only object types are covered, sorted by name,
+// including those that are not referenced at all.
+func ComputeCoverage(schema *ast.Schema, sourcePath string) []TypeCoverage {
+	files := readSourceFiles(sourcePath)
+	consumers := newConsumerIndex(files)
+
+	var docs []GQLDocument
+	for _, file := range files {
+		docs = append(docs, extractGQLFromContent(file.Path, file.Content)...)
+	}
+
+	usedBy := map[string]map[string]*fieldUse{}
+	for _, doc := range withFragments(docs) {
+		queryDoc, errs := ValidateQuery(schema, doc.Body)
+		if queryDoc == nil {
+			reportValidation(errs, doc)
+			continue
+		}
+		recordOperations(doc, queryDoc)
+
+		visited := map[string]map[string]bool{}
+		var output []string
+		for _, op := range queryDoc.Operations {
+			root := operationRoot(schema, op)
+			if root == nil || !doc.owns(op.Position) {
+				continue
+			}
+			for _, sel := range op.SelectionSet {
+				if field, ok := sel.(*ast.Field); ok {
+					if visited[root.Name] == nil {
+						visited[root.Name] = map[string]bool{}
+					}
+					visited[root.Name][field.Name] = true
+					processField(field, root, schema, visited, &output, operationLabel(op), "")
+				}
+			}
+		}
+
+		hooks, pages := consumers.consumers(doc)
+		for typeName, fields := range visited {
+			if usedBy[typeName] == nil {
+				usedBy[typeName] = map[string]*fieldUse{}
+			}
+			for fieldName := range fields {
+				use := usedBy[typeName][fieldName]
+				if use == nil {
+					use = &fieldUse{sources: map[string]bool{}, hooks: map[string]bool{}, pages: map[string]bool{}}
+					usedBy[typeName][fieldName] = use
+				}
+				use.sources[doc.SourcePath] = true
+				for _, hook := range hooks {
+					use.hooks[hook] = true
+				}
+				for _, page := range pages {
+					use.pages[page] = true
+				}
+			}
+		}
+	}
+
+	var coverages []TypeCoverage
+	for _, def := range schema.Types {
+		if def.Kind != ast.Object || def.BuiltIn || strings.HasPrefix(def.Name, "__") {
+			continue
+		}
+
+		coverage := TypeCoverage{Name: def.Name, Fields: map[string][]string{}, Hooks: map[string][]string{}, Pages: map[string][]string{}}
+		for _, f := range def.Fields {
+			if !strings.HasPrefix(f.Name, "__") {
+				coverage.Total++
+			}
+		}
+		for fieldName, use := range usedBy[def.Name] {
+			// processField may record selections the schema does not define
+			if def.Fields.ForName(fieldName) == nil {
+				continue
+			}
+			coverage.Fields[fieldName] = sortedKeys(use.sources)
+			if len(use.hooks) > 0 {
+				coverage.Hooks[fieldName] = sortedKeys(use.hooks)
+			}
+			if len(use.pages) > 0 {
+				coverage.Pages[fieldName] = sortedKeys(use.pages)
+			}
+		}
+		coverages = append(coverages, coverage)
+	}
+
+	sort.Slice(coverages, func(i, j int) bool {
+		return coverages[i].Name < coverages[j].Name
+	})
+
+	return coverages
+}
+
+func reportCoverage(schemaFilePath, sourcePath string) {
+	// Load schema
+	schema := LoadSchema(schemaFilePath)
+
+	coverages := ComputeCoverage(schema, sourcePath)
+	recordResults(coverages)
+
+	usedFields, totalFields, usedTypes := 0, 0, 0
+	for _, c := range coverages {
+		totalFields += c.Total
+		if len(c.Fields) == 0 {
+			continue
+		}
+		usedFields += len(c.Fields)
+		usedTypes++
+
+		fmt.Fprintf(stdout, "type %s: %d/%d fields (%.1f%%)\n", c.Name, len(c.Fields), c.Total, percentage(len(c.Fields), c.Total))
+		def := schema.Types[c.Name]
+		for _, f := range def.Fields {
+			if sources, exist := c.Fields[f.Name]; exist {
+				fmt.Fprintf(stdout, "  %s: %s\n", f.Name, strings.Join(sources, ", "))
+				if hooks := c.Hooks[f.Name]; len(hooks) > 0 {
+					fmt.Fprintf(stdout, "    hooks: %s\n", strings.Join(hooks, ", "))
+				}
+				if pages := c.Pages[f.Name]; len(pages) > 0 {
+					fmt.Fprintf(stdout, "    pages: %s\n", strings.Join(pages, ", "))
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(stdout, "\n-------\n\n")
+	fmt.Fprintf(stdout, "coverage: %d/%d types, %d/%d fields (%.1f%%)\n", usedTypes, len(coverages), usedFields, totalFields, percentage(usedFields, totalFields))
+}
+
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// DeprecatedUsage is a reference from a UI operation to a schema element
+// marked with @deprecated.
+type DeprecatedUsage struct {
+	// Position is the host file location, path:line:col
+	Position string `json:"position"`
+	// Kind is one of field, argument, input field or enum value
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// FindDeprecatedUsages lists every deprecated field, argument, input field and
+// enum value referenced by the operations and fragments of docs.
+func FindDeprecatedUsages(schema *ast.Schema, docs []GQLDocument) []DeprecatedUsage {
+	var usages []DeprecatedUsage
+
+	for _, doc := range docs {
+		queryDoc, errs := ValidateQuery(schema, doc.Body)
+		if queryDoc == nil {
+			reportValidation(errs, doc)
+			continue
+		}
+		recordOperations(doc, queryDoc)
+
+		for _, op := range queryDoc.Operations {
+			collectDeprecated(op.SelectionSet, doc, &usages)
+		}
+		for _, frag := range queryDoc.Fragments {
+			collectDeprecated(frag.SelectionSet, doc, &usages)
+		}
+	}
+
+	return usages
+}
+
+func collectDeprecated(selectionSet ast.SelectionSet, doc GQLDocument, usages *[]DeprecatedUsage) {
+	for _, sel := range selectionSet {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if s.Definition == nil {
+				continue
+			}
+			if reason, ok := deprecationReason(s.Definition.Directives); ok {
+				*usages = append(*usages, DeprecatedUsage{
+					Position: doc.Position(s.Position.Line, s.Position.Column),
+					Kind:     "field",
+					Name:     s.ObjectDefinition.Name + "." + s.Name,
+					Reason:   reason,
+				})
+			}
+			for _, arg := range s.Arguments {
+				argDef := s.Definition.Arguments.ForName(arg.Name)
+				if argDef == nil {
+					continue
+				}
+				if reason, ok := deprecationReason(argDef.Directives); ok {
+					*usages = append(*usages, DeprecatedUsage{
+						Position: doc.Position(arg.Position.Line, arg.Position.Column),
+						Kind:     "argument",
+						Name:     s.ObjectDefinition.Name + "." + s.Name + "(" + arg.Name + ")",
+						Reason:   reason,
+					})
+				}
+				collectDeprecatedValue(arg.Value, doc, usages)
+			}
+			collectDeprecated(s.SelectionSet, doc, usages)
+		case *ast.InlineFragment:
+			collectDeprecated(s.SelectionSet, doc, usages)
+		}
+	}
+}
+
+func collectDeprecatedValue(value *ast.Value, doc GQLDocument, usages *[]DeprecatedUsage) {
+	if value == nil || value.Definition == nil {
+		return
+	}
+
+	switch value.Kind {
+	case ast.EnumValue:
+		enumValue := value.Definition.EnumValues.ForName(value.Raw)
+		if enumValue == nil {
+			return
+		}
+		if reason, ok := deprecationReason(enumValue.Directives); ok {
+			*usages = append(*usages, DeprecatedUsage{
+				Position: doc.Position(value.Position.Line, value.Position.Column),
+				Kind:     "enum value",
+				Name:     value.Definition.Name + "." + value.Raw,
+				Reason:   reason,
+			})
+		}
+	case ast.ListValue:
+		for _, child := range value.Children {
+			collectDeprecatedValue(child.Value, doc, usages)
+		}
+	case ast.ObjectValue:
+		for _, child := range value.Children {
+			if fieldDef := value.Definition.Fields.ForName(child.Name); fieldDef != nil {
+				if reason, ok := deprecationReason(fieldDef.Directives); ok {
+					*usages = append(*usages, DeprecatedUsage{
+						Position: doc.Position(child.Position.Line, child.Position.Column),
+						Kind:     "input field",
+						Name:     value.Definition.Name + "." + child.Name,
+						Reason:   reason,
+					})
+				}
+			}
+			collectDeprecatedValue(child.Value, doc, usages)
+		}
+	}
+}
+
+// deprecationReason returns the reason of a @deprecated directive, if any.
+func deprecationReason(directives ast.DirectiveList) (string, bool) {
+	deprecated := directives.ForName("deprecated")
+	if deprecated == nil {
+		return "", false
+	}
+
+	reason := "No longer supported"
+	if arg := deprecated.Arguments.ForName("reason"); arg != nil && arg.Value != nil {
+		reason = arg.Value.Raw
+	}
+
+	return reason, true
+}
+
+func reportDeprecated(schemaFilePath, sourcePath string) {
+	// Load schema
+	schema := LoadSchema(schemaFilePath)
+
+	usages := FindDeprecatedUsages(schema, extractGQLFromPath(sourcePath))
+	recordResults(usages)
+
+	for _, u := range usages {
+		fmt.Fprintf(stdout, "%s: deprecated %s %s: %s\n", u.Position, u.Kind, u.Name, u.Reason)
+	}
+
+	fmt.Fprintf(stdout, "\n-------\n\n")
+	fmt.Fprintln(stdout, "deprecated:", len(usages), "usages")
+}
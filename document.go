@@ -0,0 +1,213 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+var (
+	// This pattern looks for 'gql`' or 'graphql`' followed by any characters until a closing backtick.
+	// It also captures the content between the backticks.
+	gqlTemplateRegex = regexp.MustCompile(`(?s)(?:gql|graphql)` + "`" + `(.*?)` + "`")
+	// Template interpolation such as ${FRAGMENT} inside the gql literal
+	interpolationRegex = regexp.MustCompile(`\$\{[^}]*\}`)
+	// Named fragment spread, "... on Type" inline fragments are filtered out
+	fragmentSpreadRegex = regexp.MustCompile(`\.\.\.\s*([_A-Za-z][_0-9A-Za-z]*)`)
+	fragmentDefRegex    = regexp.MustCompile(`fragment\s+([_A-Za-z][_0-9A-Za-z]*)\s+on\b`)
+	// Binding of the template literal, e.g. export const GET_INBOUND = gql`
+	bindingRegex = regexp.MustCompile(`(?:const|let|var)\s+(\w+)\s*(?::[^=]+)?=\s*(?:gql|graphql)$`)
+)
+
+// GQLDocument is a graphql document extracted from a template literal of a
+// host source file (.js, .ts, .tsx).
+type GQLDocument struct {
+	SourcePath string
+	// Offset is the byte offset of Body within the host file
+	Offset int
+	// Line and Column are the 1-based host file position of Offset
+	Line   int
+	Column int
+	// Name is the variable the template literal is assigned to, if any
+	Name string
+	Body string
+	// ownRunes is the rune length of Body before withFragments appended the
+	// fragment documents it spreads, 0 when nothing was appended
+	ownRunes int
+}
+
+func newGQLDocument(sourcePath, content string, start, end int) GQLDocument {
+	prefix := content[:start]
+	return GQLDocument{
+		SourcePath: sourcePath,
+		Offset:     start,
+		Line:       strings.Count(prefix, "\n") + 1,
+		Column:     start - strings.LastIndex(prefix, "\n"),
+		Name:       templateBinding(prefix),
+		// blank out interpolations so the body parses while positions stay intact
+		Body: interpolationRegex.ReplaceAllStringFunc(content[start:end], func(m string) string {
+			return strings.Repeat(" ", len(m))
+		}),
+	}
+}
+
+// templateBinding returns the variable name assigned by the gql template tag
+// ending prefix, prefix running up to the opening backtick.
+func templateBinding(prefix string) string {
+	prefix = strings.TrimSuffix(prefix, "`")
+	if lineStart := strings.LastIndex(prefix, "\n"); lineStart != -1 {
+		prefix = prefix[lineStart+1:]
+	}
+
+	if match := bindingRegex.FindStringSubmatch(prefix); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// extractGQLFromContent returns every gql/graphql template literal of content.
+func extractGQLFromContent(sourcePath, content string) (docs []GQLDocument) {
+	for _, match := range gqlTemplateRegex.FindAllStringSubmatchIndex(content, -1) {
+		docs = append(docs, newGQLDocument(sourcePath, content, match[2], match[3]))
+	}
+	return
+}
+
+// extractGQLFromPath extracts the documents of a single source file or, when
+// path is a directory, of every .js/.ts/.tsx file below it.
+func extractGQLFromPath(path string) (docs []GQLDocument) {
+	for _, file := range readSourceFiles(path) {
+		docs = append(docs, extractGQLFromContent(file.Path, file.Content)...)
+	}
+	return
+}
+
+type sourceFile struct {
+	Path    string
+	Content string
+}
+
+// readSourceFiles reads path itself when it is a file, or every .js/.ts/.tsx
+// file below it, skipping node_modules.
+func readSourceFiles(path string) (files []sourceFile) {
+	err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if info.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ext := filepath.Ext(filePath)
+		if filePath != path && ext != ".js" && ext != ".ts" && ext != ".tsx" {
+			return nil
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, sourceFile{Path: filePath, Content: string(content)})
+		return nil
+	})
+
+	if err != nil {
+		panic("⚠️ Error reading source " + path + ":" + err.Error())
+	}
+
+	return
+}
+
+// withFragments appends to each document the bodies of the documents
+// defining the fragments it spreads but does not define, transitively, as UI
+// documents usually interpolate fragments kept in another literal or file.
+// Appending keeps the positions of the original body intact.
+func withFragments(docs []GQLDocument) []GQLDocument {
+	definedBy := map[string]GQLDocument{}
+	for _, doc := range docs {
+		for _, match := range fragmentDefRegex.FindAllStringSubmatch(doc.Body, -1) {
+			definedBy[match[1]] = doc
+		}
+	}
+
+	result := make([]GQLDocument, 0, len(docs))
+	for _, doc := range docs {
+		defined := map[string]bool{}
+		for _, match := range fragmentDefRegex.FindAllStringSubmatch(doc.Body, -1) {
+			defined[match[1]] = true
+		}
+
+		body := doc.Body
+		for scanned := 0; scanned < len(body); {
+			spreads := fragmentSpreadRegex.FindAllStringSubmatch(body[scanned:], -1)
+			scanned = len(body)
+			for _, match := range spreads {
+				name := match[1]
+				fragDoc, exist := definedBy[name]
+				if name == "on" || defined[name] || !exist {
+					continue
+				}
+				for _, def := range fragmentDefRegex.FindAllStringSubmatch(fragDoc.Body, -1) {
+					defined[def[1]] = true
+				}
+				body += "\n" + fragDoc.Body
+			}
+		}
+
+		if len(body) > len(doc.Body) {
+			doc.ownRunes = utf8.RuneCountInString(doc.Body)
+		}
+		doc.Body = body
+		result = append(result, doc)
+	}
+
+	return result
+}
+
+// owns reports whether pos, parsed from Body, lies in the body of d itself
+// rather than in a fragment document appended by withFragments. Parsed
+// positions count runes, not bytes.
+func (d GQLDocument) owns(pos *ast.Position) bool {
+	return d.ownRunes == 0 || pos.Start < d.ownRunes
+}
+
+// Position maps a 1-based line and column inside Body back to the host file,
+// formatted as path:line:col so editors can jump to it.
+func (d GQLDocument) Position(line, column int) string {
+	if line == 1 {
+		column += d.Column - 1
+	}
+	line += d.Line - 1
+
+	return d.SourcePath + ":" + strconv.Itoa(line) + ":" + strconv.Itoa(column)
+}
+
+// ErrorPosition returns the host file position of a parse or validation
+// error, falling back to the document start when the error has no location.
+func (d GQLDocument) ErrorPosition(e *gqlerror.Error) string {
+	if len(e.Locations) == 0 {
+		return d.Position(1, 1)
+	}
+
+	return d.Position(e.Locations[0].Line, e.Locations[0].Column)
+}
+
+// describeError returns "position: message" of an error parsing the body of
+// d, positioned in the host file.
+func (d GQLDocument) describeError(err error) string {
+	if gqlErr, ok := err.(*gqlerror.Error); ok {
+		return d.ErrorPosition(gqlErr) + ": " + gqlErr.Message
+	}
+	return d.Position(1, 1) + ": " + err.Error()
+}
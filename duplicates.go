@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// OperationCollision is an operation name defined by more than one UI
+// document, which Apollo treats as the same operation.
+type OperationCollision struct {
+	Name      string   `json:"name"`
+	Positions []string `json:"positions"`
+	// Diffs compares every later definition with the first one
+	Diffs []SelectionDiff `json:"diffs"`
+}
+
+// SelectionDiff lists the selection paths, fragments expanded, a definition
+// lacks or adds compared to the first definition of the same name.
+type SelectionDiff struct {
+	Position string   `json:"position"`
+	Removed  []string `json:"removed"`
+	Added    []string `json:"added"`
+}
+
+// operationIndex lists the definitions of each operation name in source
+// order, shared by duplicates and the duplicate-operation-name lint rule.
+type operationIndex map[string][]indexedOperation
+
+type indexedOperation struct {
+	Position  string
+	Operation *ast.OperationDefinition
+}
+
+// add indexes op of doc, anonymous operations having no name to collide on.
+func (index operationIndex) add(doc GQLDocument, op *ast.OperationDefinition) {
+	if op.Name == "" {
+		return
+	}
+	index[op.Name] = append(index[op.Name], indexedOperation{
+		Position:  doc.Position(op.Position.Line, op.Position.Column),
+		Operation: op,
+	})
+}
+
+// duplicates returns the names defined more than once, sorted.
+func (index operationIndex) duplicates() (names []string) {
+	for name, defs := range index {
+		if len(defs) > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+// FindOperationCollisions indexes the named operations of docs and returns
+// the names defined more than once, sorted by name.
+func FindOperationCollisions(schema *ast.Schema, docs []GQLDocument) []OperationCollision {
+	index := operationIndex{}
+
+	for _, doc := range withFragments(docs) {
+		queryDoc, errs := ValidateQuery(schema, doc.Body)
+		if queryDoc == nil {
+			reportValidation(errs, doc)
+			continue
+		}
+		recordOperations(doc, queryDoc)
+
+		for _, op := range queryDoc.Operations {
+			if doc.owns(op.Position) {
+				index.add(doc, op)
+			}
+		}
+	}
+
+	var collisions []OperationCollision
+	for _, name := range index.duplicates() {
+		defs := index[name]
+		collision := OperationCollision{Name: name}
+		var paths [][]string
+		for _, def := range defs {
+			collision.Positions = append(collision.Positions, def.Position)
+			paths = append(paths, selectionPaths(def.Operation.SelectionSet, "", map[string]bool{}))
+		}
+		for i, def := range defs[1:] {
+			collision.Diffs = append(collision.Diffs, SelectionDiff{
+				Position: def.Position,
+				Removed:  missingPaths(paths[0], paths[i+1]),
+				Added:    missingPaths(paths[i+1], paths[0]),
+			})
+		}
+		collisions = append(collisions, collision)
+	}
+
+	return collisions
+}
+
+// selectionPaths flattens selectionSet into sorted dotted response paths,
+// fragment spreads expanded and inline fragments marked with "... on Type".
+func selectionPaths(selectionSet ast.SelectionSet, prefix string, visited map[string]bool) []string {
+	var paths []string
+	for _, sel := range selectionSet {
+		switch s := sel.(type) {
+		case *ast.Field:
+			path := prefix + s.Alias
+			if s.Alias != s.Name {
+				path += ":" + s.Name
+			}
+			paths = append(paths, path)
+			paths = append(paths, selectionPaths(s.SelectionSet, path+".", visited)...)
+		case *ast.InlineFragment:
+			paths = append(paths, selectionPaths(s.SelectionSet, prefix+"... on "+s.TypeCondition+".", visited)...)
+		case *ast.FragmentSpread:
+			if s.Definition != nil && !visited[s.Name] {
+				visited[s.Name] = true
+				paths = append(paths, selectionPaths(s.Definition.SelectionSet, prefix, visited)...)
+				delete(visited, s.Name)
+			}
+		}
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// missingPaths returns the paths of from absent in to.
+func missingPaths(from, to []string) (missing []string) {
+	for _, path := range from {
+		if !containsString(to, path) {
+			missing = append(missing, path)
+		}
+	}
+	return
+}
+
+func reportDuplicates(schemaFilePath, sourcePath string) {
+	// Load schema
+	schema := LoadSchema(schemaFilePath)
+
+	collisions := FindOperationCollisions(schema, extractGQLFromPath(sourcePath))
+	recordResults(collisions)
+
+	for _, c := range collisions {
+		fmt.Fprintf(stdout, "operation %s defined %d times:\n", c.Name, len(c.Positions))
+		fmt.Fprintf(stdout, "  %s\n", c.Positions[0])
+		for _, diff := range c.Diffs {
+			fmt.Fprintf(stdout, "  %s\n", diff.Position)
+			if len(diff.Removed) == 0 && len(diff.Added) == 0 {
+				fmt.Fprintln(stdout, "    same selection")
+			}
+			for _, path := range diff.Removed {
+				fmt.Fprintf(stdout, "    - %s\n", path)
+			}
+			for _, path := range diff.Added {
+				fmt.Fprintf(stdout, "    + %s\n", path)
+			}
+		}
+	}
+
+	fmt.Fprintf(stdout, "\n-------\n\n")
+	fmt.Fprintln(stdout, "duplicates:", len(collisions), "operation names")
+	if len(collisions) > 0 && opts.Strict {
+		fatalf("%d duplicate operation names", len(collisions))
+	}
+}
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"golang.org/x/term"
+)
+
+const exploreHelp = `commands:
+  find <text>       list the types whose name contains text
+  type <name>       open a type
+  <n>               open the nth listed type or the type of the nth field
+  back              return to the previous type
+  mark <n> [n...]   mark or unmark fields of the current type
+  marks             list the marked field paths
+  emit type         print the trimmed types of the marked fields
+  emit query        print a query or fragment document of the marked fields
+  help              print this help
+  quit              leave
+`
+
+// exploreFrame is an opened type, reached through Field of the previous one.
+type exploreFrame struct {
+	Type  string
+	Field string
+}
+
+// explorer is the state of an explore session. Marks are dotted field paths
+// keyed by the type the navigation started from.
+type explorer struct {
+	schema  *ast.Schema
+	out     io.Writer
+	stack   []exploreFrame
+	listing []string
+	// fieldsListed tells listing holds the field types of the current type
+	fieldsListed bool
+	marks        map[string]map[string]bool
+}
+
+// Explore runs an interactive schema browsing session reading commands from
+// in until quit or end of input.
+func Explore(schema *ast.Schema, in io.Reader, out io.Writer) {
+	e := &explorer{schema: schema, out: out, marks: map[string]map[string]bool{}}
+	fmt.Fprint(out, exploreHelp)
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, e.prompt())
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return
+		}
+
+		command, arg, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		arg = strings.TrimSpace(arg)
+		switch command {
+		case "":
+		case "quit", "exit", "q":
+			return
+		case "help":
+			fmt.Fprint(out, exploreHelp)
+		case "find":
+			e.find(arg)
+		case "type":
+			e.open(arg, true)
+		case "back":
+			if len(e.stack) > 1 {
+				e.stack = e.stack[:len(e.stack)-1]
+				e.show()
+			}
+		case "mark":
+			e.mark(strings.Fields(arg))
+		case "marks":
+			for _, start := range sortedKeys(e.marks) {
+				for _, path := range sortedKeys(e.marks[start]) {
+					fmt.Fprintf(out, "  %s.%s\n", start, path)
+				}
+			}
+		case "emit":
+			e.emit(arg)
+		default:
+			n, err := strconv.Atoi(command)
+			if err != nil || n < 1 || n > len(e.listing) {
+				fmt.Fprintf(out, "unknown command %s, type help\n", command)
+				continue
+			}
+			e.follow(n - 1)
+		}
+	}
+}
+
+func (e *explorer) prompt() string {
+	if len(e.stack) == 0 {
+		return "> "
+	}
+	var path []string
+	for _, frame := range e.stack[1:] {
+		path = append(path, frame.Field)
+	}
+	return strings.Join(append([]string{e.stack[0].Type}, path...), ".") + " > "
+}
+
+func (e *explorer) find(text string) {
+	e.listing = e.matchTypes(text)
+	e.fieldsListed = false
+
+	for i, name := range e.listing {
+		fmt.Fprintf(e.out, "  %d. %s %s\n", i+1, kindName(e.schema.Types[name].Kind), name)
+	}
+	fmt.Fprintln(e.out, len(e.listing), "types")
+}
+
+// matchTypes returns the sorted names of the types containing text.
+func (e *explorer) matchTypes(text string) (names []string) {
+	for name, def := range e.schema.Types {
+		if def.BuiltIn || strings.HasPrefix(name, "__") || ignored.IgnoresType(name) {
+			continue
+		}
+		if strings.Contains(strings.ToLower(name), strings.ToLower(text)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+// open shows typeName, starting a new navigation when reset is set.
+func (e *explorer) open(typeName string, reset bool) {
+	if e.schema.Types[typeName] == nil {
+		fmt.Fprintf(e.out, "type %s not found%s\n", typeName, didYouMean(typeName, typeNames(e.schema)))
+		return
+	}
+	if reset {
+		e.stack = nil
+	}
+	e.stack = append(e.stack, exploreFrame{Type: typeName})
+	e.show()
+}
+
+// follow opens the nth listed type, or the type of the nth field.
+func (e *explorer) follow(n int) {
+	if !e.fieldsListed {
+		e.open(e.listing[n], true)
+		return
+	}
+
+	def := e.schema.Types[e.stack[len(e.stack)-1].Type]
+	field := e.fields(def)[n]
+	if e.schema.Types[field.Type.Name()].BuiltIn {
+		fmt.Fprintf(e.out, "%s is a %s\n", field.Name, field.Type.String())
+		return
+	}
+	e.stack = append(e.stack, exploreFrame{Type: field.Type.Name(), Field: field.Name})
+	e.show()
+}
+
+func (e *explorer) show() {
+	def := e.schema.Types[e.stack[len(e.stack)-1].Type]
+	fmt.Fprintf(e.out, "%s %s\n", kindName(def.Kind), def.Name)
+	if def.Description != "" {
+		fmt.Fprintf(e.out, "  %s\n", def.Description)
+	}
+
+	e.listing = nil
+	e.fieldsListed = true
+	for _, v := range def.EnumValues {
+		fmt.Fprintf(e.out, "  %s\n", v.Name)
+	}
+
+	marked := e.marks[e.stack[0].Type]
+	for i, f := range e.fields(def) {
+		e.listing = append(e.listing, f.Type.Name())
+
+		mark := " "
+		if marked[e.path(f.Name)] {
+			mark = "*"
+		}
+		fmt.Fprintf(e.out, " %s%d. %s", mark, i+1, fieldSignature(f))
+		if f.Description != "" {
+			fmt.Fprintf(e.out, " # %s", strings.ReplaceAll(f.Description, "\n", " "))
+		}
+		fmt.Fprintln(e.out)
+	}
+}
+
+// fieldSignature returns the SDL of f without its description, e.g.
+// stock(limit: Int): [stock!]!
+func fieldSignature(f *ast.FieldDefinition) string {
+	var args []string
+	for _, arg := range f.Arguments {
+		args = append(args, arg.Name+": "+arg.Type.String())
+	}
+	argStr := ""
+	if len(args) > 0 {
+		argStr = "(" + strings.Join(args, ", ") + ")"
+	}
+	return f.Name + argStr + ": " + f.Type.String()
+}
+
+// fields returns the fields of def listed by show, introspection and
+// ignored fields left out.
+func (e *explorer) fields(def *ast.Definition) (fields ast.FieldList) {
+	for _, f := range keptFields(def) {
+		if !strings.HasPrefix(f.Name, "__") {
+			fields = append(fields, f)
+		}
+	}
+	return
+}
+
+// kindName returns the SDL keyword of kind.
+func kindName(kind ast.DefinitionKind) string {
+	switch kind {
+	case ast.Object:
+		return "type"
+	case ast.InputObject:
+		return "input"
+	default:
+		return strings.ToLower(string(kind))
+	}
+}
+
+// path returns the dotted path of field of the current type from the type
+// the navigation started from.
+func (e *explorer) path(field string) string {
+	var path []string
+	for _, frame := range e.stack[1:] {
+		path = append(path, frame.Field)
+	}
+	return strings.Join(append(path, field), ".")
+}
+
+func (e *explorer) mark(args []string) {
+	if len(e.stack) == 0 {
+		fmt.Fprintln(e.out, "open a type first")
+		return
+	}
+
+	def := e.schema.Types[e.stack[len(e.stack)-1].Type]
+	fields := e.fields(def)
+	for _, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > len(fields) {
+			fmt.Fprintf(e.out, "no field %s\n", arg)
+			continue
+		}
+		e.toggleMark(fields[n-1].Name)
+	}
+	e.show()
+}
+
+// toggleMark marks or unmarks field of the current type.
+func (e *explorer) toggleMark(field string) {
+	start := e.stack[0].Type
+	if e.marks[start] == nil {
+		e.marks[start] = map[string]bool{}
+	}
+	path := e.path(field)
+	if e.marks[start][path] {
+		delete(e.marks[start], path)
+		if len(e.marks[start]) == 0 {
+			delete(e.marks, start)
+		}
+	} else {
+		e.marks[start][path] = true
+	}
+}
+
+func (e *explorer) emit(what string) {
+	if what != "type" && what != "query" {
+		fmt.Fprintln(e.out, "emit type or emit query")
+		return
+	}
+
+	for _, start := range sortedKeys(e.marks) {
+		def := e.schema.Types[start]
+		if what == "query" && def.Kind != ast.Object && def.Kind != ast.Interface {
+			fmt.Fprintf(e.out, "%s is an %s, only object types can be queried, use emit type\n", def.Name, sdlKeywords[def.Kind])
+			continue
+		}
+		var selectionSet ast.SelectionSet
+		for _, path := range sortedKeys(e.marks[start]) {
+			selectionSet = insertPath(selectionSet, strings.Split(path, "."))
+		}
+		fillSelections(e.schema, def, selectionSet)
+
+		if what == "type" {
+			var outputs []string
+			processSelectionSet(selectionSet, def, e.schema, map[string]map[string]bool{}, &outputs, "explore", "")
+			for _, output := range outputs {
+				if strings.TrimSpace(output) != "" && strings.TrimSpace(output) != "}" {
+					fmt.Fprintln(e.out, output)
+				}
+			}
+			continue
+		}
+
+		doc := &ast.QueryDocument{}
+		switch {
+		case e.schema.Query != nil && def.Name == e.schema.Query.Name:
+			doc.Operations = ast.OperationList{{Operation: ast.Query, SelectionSet: selectionSet}}
+		case e.schema.Mutation != nil && def.Name == e.schema.Mutation.Name:
+			doc.Operations = ast.OperationList{{Operation: ast.Mutation, SelectionSet: selectionSet}}
+		default:
+			doc.Fragments = ast.FragmentDefinitionList{{Name: def.Name + "Fields", TypeCondition: def.Name, SelectionSet: selectionSet}}
+		}
+		var buf bytes.Buffer
+		formatter.NewFormatter(&buf, formatter.WithIndent("  ")).FormatQueryDocument(doc)
+		fmt.Fprint(e.out, buf.String())
+	}
+}
+
+// insertPath adds the fields of path to selectionSet, sharing the fields
+// already selected.
+func insertPath(selectionSet ast.SelectionSet, path []string) ast.SelectionSet {
+	if len(path) == 0 {
+		return selectionSet
+	}
+	for _, sel := range selectionSet {
+		if field, ok := sel.(*ast.Field); ok && field.Name == path[0] {
+			field.SelectionSet = insertPath(field.SelectionSet, path[1:])
+			return selectionSet
+		}
+	}
+	return append(selectionSet, &ast.Field{Name: path[0], Alias: path[0], SelectionSet: insertPath(nil, path[1:])})
+}
+
+// fillSelections selects the scalar fields of the marked object and input
+// fields having no marked field of their own.
+func fillSelections(schema *ast.Schema, def *ast.Definition, selectionSet ast.SelectionSet) {
+	for _, sel := range selectionSet {
+		field := sel.(*ast.Field)
+		fieldDef := def.Fields.ForName(field.Name)
+		if fieldDef == nil {
+			continue
+		}
+		nested := schema.Types[fieldDef.Type.Name()]
+		if nested.Kind != ast.Object && nested.Kind != ast.Interface && nested.Kind != ast.InputObject {
+			continue
+		}
+		if len(field.SelectionSet) == 0 {
+			field.SelectionSet = scalarSelection(schema, nested, 1)
+		} else {
+			fillSelections(schema, nested, field.SelectionSet)
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func exploreSchema(schemaFilePath string) {
+	if report != nil {
+		fatalf("explore is interactive, it has no json output")
+	}
+
+	// Load schema
+	schema := LoadSchema(schemaFilePath)
+
+	// the full screen explorer needs a terminal, pipes get the line prompt
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(in) || !term.IsTerminal(out) {
+		Explore(schema, os.Stdin, stdout)
+		return
+	}
+
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		fatalf("cannot set up the terminal: %v", err)
+	}
+	// alternate screen, cursor hidden
+	fmt.Fprint(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	emitted := exploreTerminal(schema, os.Stdin, os.Stdout, func() (int, int) {
+		width, height, err := term.GetSize(out)
+		if err != nil {
+			return 80, 24
+		}
+		return width, height
+	})
+	fmt.Fprint(os.Stdout, "\x1b[?25h\x1b[?1049l")
+	term.Restore(in, state)
+
+	for _, doc := range emitted {
+		fmt.Fprintln(stdout, doc)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Hooks for main_test into the helpers driven by the flags.
+
+var (
+	TrimmedOutputs      = trimmedOutputs
+	SourceOperations    = sourceOperations
+	ParseFieldSelection = parseFieldSelection
+	CheckSelection      = checkSelection
+	SkeletonDocument    = skeletonDocument
+)
+
+// PrintSchemaField returns what --type prints for typeName.
+func PrintSchemaField(schema *ast.Schema, typeName string, depth uint) []string {
+	var outputs []string
+	printSchemaField(schema, typeName, map[string]bool{}, &outputs, depth)
+	return outputs
+}
+
+// ProcessSelectionSet returns the trimmed types --type --fields prints.
+func ProcessSelectionSet(schema *ast.Schema, def *ast.Definition, selectionSet ast.SelectionSet) []string {
+	var outputs []string
+	processSelectionSet(selectionSet, def, schema, map[string]map[string]bool{}, &outputs, "type "+def.Name, "")
+	return outputs
+}
+
+// WithFlags runs fn with --max-types and the ignored file set, and returns
+// the warnings it reported.
+func WithFlags(maxTypes uint, ignoreList IgnoreList, fn func()) []string {
+	prevMaxTypes, prevIgnored, prevReport := opts.MaxTypes, ignored, report
+	defer func() {
+		opts.MaxTypes, ignored, report = prevMaxTypes, prevIgnored, prevReport
+	}()
+
+	opts.MaxTypes, ignored = maxTypes, ignoreList
+	report = &jsonReport{Warnings: []string{}}
+	fn()
+	return report.Warnings
+}
+
+// ExploreTerminal runs the full screen explorer on a width x height screen.
+func ExploreTerminal(schema *ast.Schema, in io.Reader, out io.Writer, width, height int) []string {
+	return exploreTerminal(schema, in, out, func() (int, int) { return width, height })
+}
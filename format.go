@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// FormatSource reformats every gql literal of a source file with the
+// gqlparser formatter and returns the new content. Definitions keep their
+// order and the literal keeps its base indentation. Interpolations between
+// definitions, such as a trailing ${FRAGMENT}, are kept in place; literals
+// interpolating inside a definition are left untouched.
+func FormatSource(path, content string, sortSelections bool) string {
+	docs := extractGQLFromContent(path, content)
+
+	// replace from the end so earlier offsets stay valid
+	for i := len(docs) - 1; i >= 0; i-- {
+		doc := docs[i]
+		raw := content[doc.Offset : doc.Offset+len(doc.Body)]
+		body, ok := formatDocument(doc, raw, sortSelections)
+		if !ok {
+			continue
+		}
+		content = content[:doc.Offset] + body + content[doc.Offset+len(doc.Body):]
+	}
+
+	return content
+}
+
+// formatDocument returns the formatted body of doc, raw being the literal
+// as written, interpolations included.
+func formatDocument(doc GQLDocument, raw string, sortSelections bool) (string, bool) {
+	queryDoc, err := parser.ParseQuery(&ast.Source{Input: doc.Body, Name: doc.SourcePath})
+	if err != nil {
+		warnf("%s, not formatted", doc.describeError(err))
+		return "", false
+	}
+
+	type chunk struct {
+		Offset int
+		Text   string
+	}
+	var chunks []chunk
+
+	for _, m := range interpolationRegex.FindAllStringIndex(raw, -1) {
+		if depth := strings.Count(raw[:m[0]], "{") - strings.Count(raw[:m[0]], "}"); depth != 0 {
+			warnf("%s: interpolation inside a definition, not formatted", doc.Position(1, 1))
+			return "", false
+		}
+		chunks = append(chunks, chunk{Offset: m[0], Text: raw[m[0]:m[1]] + "\n"})
+	}
+
+	runeOffsets := runeByteOffsets(doc.Body)
+	format := func(def *ast.QueryDocument) string {
+		var buf bytes.Buffer
+		formatter.NewFormatter(&buf, formatter.WithIndent("  "), formatter.WithComments()).FormatQueryDocument(def)
+		return buf.String()
+	}
+	for _, op := range queryDoc.Operations {
+		if sortSelections {
+			sortSelectionSet(op.SelectionSet)
+		}
+		chunks = append(chunks, chunk{Offset: runeOffsets[op.Position.Start], Text: format(&ast.QueryDocument{Operations: ast.OperationList{op}})})
+	}
+	for _, frag := range queryDoc.Fragments {
+		if sortSelections {
+			sortSelectionSet(frag.SelectionSet)
+		}
+		chunks = append(chunks, chunk{Offset: runeOffsets[frag.Position.Start], Text: format(&ast.QueryDocument{Fragments: ast.FragmentDefinitionList{frag}})})
+	}
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i].Offset < chunks[j].Offset
+	})
+
+	texts := make([]string, 0, len(chunks)+1)
+	for _, c := range chunks {
+		texts = append(texts, c.Text)
+	}
+	// comments trailing the last definition
+	if queryDoc.Comment != nil {
+		texts = append(texts, format(&ast.QueryDocument{Comment: queryDoc.Comment}))
+	}
+
+	// indent as the first line of the literal, closing backtick as before
+	indent := ""
+	for _, line := range strings.Split(raw, "\n") {
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != "" {
+			indent = line[:len(line)-len(trimmed)]
+			break
+		}
+	}
+	closing := ""
+	if idx := strings.LastIndex(raw, "\n"); idx != -1 && strings.TrimSpace(raw[idx:]) == "" {
+		closing = raw[idx+1:]
+	}
+
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(strings.Join(texts, "\n"), "\n"), "\n") {
+		sb.WriteString("\n")
+		if line != "" {
+			sb.WriteString(indent + line)
+		}
+	}
+	sb.WriteString("\n" + closing)
+
+	return sb.String(), true
+}
+
+// sortSelectionSet sorts fields by response name, fragment spreads and
+// inline fragments following them in their original order.
+func sortSelectionSet(selectionSet ast.SelectionSet) {
+	key := func(sel ast.Selection) string {
+		if field, ok := sel.(*ast.Field); ok {
+			return field.Alias
+		}
+		return ""
+	}
+	sort.SliceStable(selectionSet, func(i, j int) bool {
+		ki, kj := key(selectionSet[i]), key(selectionSet[j])
+		if ki == "" || kj == "" {
+			return ki != "" && kj == ""
+		}
+		return ki < kj
+	})
+
+	for _, sel := range selectionSet {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			sortSelectionSet(sel.SelectionSet)
+		case *ast.InlineFragment:
+			sortSelectionSet(sel.SelectionSet)
+		}
+	}
+}
+
+func formatSources(sourcePath string, check, sortSelections bool) {
+	var changed []string
+	for _, file := range readSourceFiles(sourcePath) {
+		content := FormatSource(file.Path, file.Content, sortSelections)
+		if content == file.Content {
+			continue
+		}
+		changed = append(changed, file.Path)
+		if check {
+			fmt.Fprintln(stdout, "not formatted:", file.Path)
+			continue
+		}
+		if err := os.WriteFile(file.Path, []byte(content), 0o644); err != nil {
+			fatalf("cannot write %s: %v", file.Path, err)
+		}
+		fmt.Fprintln(stdout, "formatted:", file.Path)
+	}
+	recordResults(changed)
+
+	if check && len(changed) > 0 {
+		fatalf("%d files are not formatted", len(changed))
+	}
+	fmt.Fprintf(stdout, "\n-------\n\n")
+	fmt.Fprintln(stdout, "fmt:", len(changed), "files")
+}
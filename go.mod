@@ -2,11 +2,15 @@ module github.com/toshim45/gqlsch
 
 go 1.22.8
 
-require github.com/vektah/gqlparser/v2 v2.5.25
+require (
+	github.com/vektah/gqlparser/v2 v2.5.25
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require golang.org/x/sys v0.21.0 // indirect
 
 require (
-	github.com/agnivade/levenshtein v1.2.1 // indirect
+	github.com/agnivade/levenshtein v1.2.1
 	github.com/jessevdk/go-flags v1.6.1
+	golang.org/x/term v0.21.0
 )
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"gopkg.in/yaml.v3"
+)
+
+var defaultScalarBindings = map[string]string{
+	"uuid":        "github.com/99designs/gqlgen/graphql.UUID",
+	"timestamptz": "github.com/99designs/gqlgen/graphql.Time",
+	"timestamp":   "github.com/99designs/gqlgen/graphql.Time",
+	"json":        "github.com/99designs/gqlgen/graphql.Map",
+	"jsonb":       "github.com/99designs/gqlgen/graphql.Map",
+	"numeric":     "github.com/99designs/gqlgen/graphql.Float",
+	"bigint":      "github.com/99designs/gqlgen/graphql.Int64",
+}
+
+// scalarBindings merges the scalar=go/pkg.Type specs into the defaults.
+func scalarBindings(specs []string) map[string]string {
+	bindings := map[string]string{}
+	for scalar, goType := range defaultScalarBindings {
+		bindings[scalar] = goType
+	}
+
+	for _, spec := range specs {
+		for _, s := range strings.Split(spec, ",") {
+			scalar, goType, ok := strings.Cut(strings.TrimSpace(s), "=")
+			if !ok || scalar == "" || goType == "" {
+				fatalf("invalid scalar binding %s, the format must be scalar=go/pkg.Type", s)
+			}
+			bindings[scalar] = goType
+		}
+	}
+
+	return bindings
+}
+
+// goInitialisms are the default common initialisms of gqlgen, extended by
+// --initialism the way go_initialisms extends them in gqlgen.yml.
+var goInitialisms = map[string]bool{
+	"acl": true, "api": true, "ascii": true, "aws": true, "cpu": true, "css": true, "csv": true,
+	"dns": true, "eof": true, "gcp": true, "guid": true, "html": true, "http": true, "https": true,
+	"icmp": true, "id": true, "ip": true, "json": true, "kvk": true, "lhs": true, "pdf": true,
+	"pgp": true, "qps": true, "qr": true, "ram": true, "rhs": true, "rpc": true, "sla": true,
+	"smtp": true, "sql": true, "ssh": true, "svg": true, "tcp": true, "tls": true, "ttl": true,
+	"udp": true, "ui": true, "uid": true, "uri": true, "url": true, "utf8": true, "uuid": true,
+	"vm": true, "xml": true, "xmpp": true, "xsrf": true, "xss": true,
+}
+
+// isInitialism reports whether part is a gqlgen initialism or one given by
+// --initialism.
+func isInitialism(part string) bool {
+	part = strings.ToLower(part)
+	if goInitialisms[part] {
+		return true
+	}
+	for _, spec := range opts.Initialisms {
+		for _, initialism := range strings.Split(spec, ",") {
+			if strings.ToLower(strings.TrimSpace(initialism)) == part {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// goName converts a schema name to an exported Go identifier the way gqlgen
+// does for the common cases, e.g. stock_inventory_id to StockInventoryID.
+func goName(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if isInitialism(part) {
+			sb.WriteString(strings.ToUpper(part))
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
+
+// modelBindings returns the gqlgen model of every emitted definition and of
+// the custom scalars their fields refer to. Types are only bound when a model
+// package is given, scalars without a Go type are reported and skipped.
+func modelBindings(schema *ast.Schema, defs []*ast.Definition, scalars map[string]string, modelPkg string) map[string]string {
+	bindings := map[string]string{}
+
+	bindScalar := func(name string) {
+		def := schema.Types[name]
+		if def == nil || def.Kind != ast.Scalar || def.BuiltIn {
+			return
+		}
+		if _, exist := bindings[name]; exist {
+			return
+		}
+		goType, exist := scalars[name]
+		if !exist {
+			warnf("no Go type for scalar %s, bind it with --scalar %s=go/pkg.Type", name, name)
+			return
+		}
+		bindings[name] = goType
+	}
+
+	for _, def := range defs {
+		if def.Kind == ast.Scalar {
+			bindScalar(def.Name)
+			continue
+		}
+		if modelPkg != "" {
+			bindings[def.Name] = modelPkg + "." + goName(def.Name)
+		}
+		for _, f := range def.Fields {
+			bindScalar(f.Type.Name())
+			for _, arg := range f.Arguments {
+				bindScalar(arg.Type.Name())
+			}
+		}
+	}
+
+	return bindings
+}
+
+// WriteGqlgenModels adds the bindings of defs to the models section of the
+// gqlgen.yml at path, creating the file if needed. Existing model entries are
+// kept as they are, so hand made bindings survive. scalarSpecs and modelPkg
+// are --scalar and --model-package.
+func WriteGqlgenModels(path string, schema *ast.Schema, defs []*ast.Definition, scalarSpecs []string, modelPkg string) {
+	bindings := modelBindings(schema, defs, scalarBindings(scalarSpecs), modelPkg)
+
+	var doc yaml.Node
+	if content, err := os.ReadFile(path); err == nil {
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			fatalf("cannot parse %s: %v", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		fatalf("cannot read %s: %v", path, err)
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		fatalf("cannot update %s: top level is not a mapping", path)
+	}
+
+	models := mappingValue(root, "models")
+	if models == nil {
+		models = &yaml.Node{Kind: yaml.MappingNode}
+		root.Content = append(root.Content, scalarNode("models"), models)
+	}
+
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	added := 0
+	for _, name := range names {
+		if mappingValue(models, name) != nil {
+			continue
+		}
+		model := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			scalarNode("model"),
+			{Kind: yaml.SequenceNode, Content: []*yaml.Node{scalarNode(bindings[name])}},
+		}}
+		models.Content = append(models.Content, scalarNode(name), model)
+		added++
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		fatalf("cannot encode %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		fatalf("cannot write %s: %v", path, err)
+	}
+
+	fmt.Fprintln(stdout, "bound", added, "models in", path)
+}
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
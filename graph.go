@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// RenderGraph returns the type reference graph of defs as Graphviz DOT or
+// Mermaid, objects, interfaces, inputs and enums being the nodes and fields
+// the edges. Fields referencing types outside defs are left out.
+func RenderGraph(defs []*ast.Definition, format string) string {
+	nodes := map[string]*ast.Definition{}
+	for _, def := range defs {
+		switch def.Kind {
+		case ast.Object, ast.Interface, ast.InputObject, ast.Enum:
+			nodes[def.Name] = def
+		}
+	}
+
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	if format == "mermaid" {
+		sb.WriteString("graph LR\n")
+	} else {
+		sb.WriteString("digraph schema {\n  rankdir=LR;\n")
+	}
+
+	for _, name := range names {
+		def := nodes[name]
+		if format == "mermaid" {
+			switch def.Kind {
+			case ast.Enum:
+				fmt.Fprintf(&sb, "  %s([\"enum %s\"])\n", name, name)
+			case ast.InputObject:
+				fmt.Fprintf(&sb, "  %s[/\"input %s\"/]\n", name, name)
+			case ast.Interface:
+				fmt.Fprintf(&sb, "  %s{{\"interface %s\"}}\n", name, name)
+			default:
+				fmt.Fprintf(&sb, "  %s[\"%s\"]\n", name, name)
+			}
+			continue
+		}
+
+		shape := map[ast.DefinitionKind]string{ast.Enum: "ellipse", ast.InputObject: "note", ast.Interface: "component"}[def.Kind]
+		if shape == "" {
+			shape = "box"
+		}
+		fmt.Fprintf(&sb, "  %q [shape=%s];\n", name, shape)
+	}
+
+	for _, name := range names {
+		for _, f := range nodes[name].Fields {
+			nested := f.Type.Name()
+			if nodes[nested] == nil {
+				continue
+			}
+			if format == "mermaid" {
+				fmt.Fprintf(&sb, "  %s -->|%s| %s\n", name, f.Name, nested)
+			} else {
+				fmt.Fprintf(&sb, "  %q -> %q [label=%q];\n", name, nested, f.Name)
+			}
+		}
+	}
+
+	if format != "mermaid" {
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+// GraphDefinitions returns the definitions to draw: the trimmed types of the
+// operations of sourcePath, the --type subtree, or the whole schema.
+func GraphDefinitions(schema *ast.Schema, sourcePath, typeName string, depth uint) []*ast.Definition {
+	if sourcePath != "" {
+		// each document trims the types it selects, merge them field-wise
+		var defs ast.DefinitionList
+		for _, doc := range extractGQLFromPath(sourcePath) {
+			queryDoc, errs := ValidateQuery(schema, doc.Body)
+			if queryDoc == nil {
+				reportValidation(errs, doc)
+				continue
+			}
+			recordOperations(doc, queryDoc)
+			defs = mergeDefinitions(defs, parseEmitted(trimmedOutputs(schema, queryDoc)))
+		}
+		return defs
+	}
+
+	if typeName != "" {
+		var outputs []string
+		printSchemaField(schema, typeName, map[string]bool{}, &outputs, depth)
+		return parseEmitted(outputs)
+	}
+
+	var defs []*ast.Definition
+	for _, def := range schema.Types {
+		if def.BuiltIn || strings.HasPrefix(def.Name, "__") || ignored.IgnoresType(def.Name) {
+			continue
+		}
+		kept := *def
+		kept.Fields = keptFields(def)
+		defs = append(defs, &kept)
+	}
+	return defs
+}
+
+func reportGraph(schemaFilePath, sourcePath, typeName string, depth uint, format string) {
+	if format != "dot" && format != "mermaid" {
+		fatalf("unknown graph format %s, use dot or mermaid", format)
+	}
+
+	// Load schema
+	schema := LoadSchema(schemaFilePath)
+
+	graph := RenderGraph(GraphDefinitions(schema, sourcePath, typeName, depth), format)
+	recordResults(graph)
+
+	fmt.Fprint(stdout, graph)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is a line of the ignored file, matching type names or, when
+// field is set, the fields of the matching types.
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	field   *regexp.Regexp
+	negate  bool
+}
+
+// IgnoreList holds the patterns of the ignored file. The last matching
+// pattern wins, so a later !Name keeps a type an earlier glob ignores.
+type IgnoreList struct {
+	rules []ignoreRule
+}
+
+// ParseIgnoreList parses one pattern per line: an exact name, a glob such as
+// Ignored* or *_aggregate_fields, a /regex/, any of them negated with a
+// leading !. Type.field and *.field_pattern globs ignore fields instead of
+// types. Blank lines and # comments are skipped.
+func ParseIgnoreList(content string) (IgnoreList, error) {
+	var list IgnoreList
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = strings.TrimSpace(line[1:])
+		}
+
+		var err error
+		if len(line) > 1 && strings.HasPrefix(line, "/") && strings.HasSuffix(line, "/") {
+			rule.pattern, err = regexp.Compile(line[1 : len(line)-1])
+		} else if typeGlob, fieldGlob, ok := strings.Cut(line, "."); ok {
+			rule.pattern = globPattern(typeGlob)
+			rule.field = globPattern(fieldGlob)
+		} else {
+			rule.pattern = globPattern(line)
+		}
+		if err != nil {
+			return list, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		list.rules = append(list.rules, rule)
+	}
+
+	return list, nil
+}
+
+// globPattern compiles a glob where * matches any run and ? a single
+// character.
+func globPattern(glob string) *regexp.Regexp {
+	return regexp.MustCompile("^" + strings.NewReplacer(`\*`, ".*", `\?`, ".").Replace(regexp.QuoteMeta(glob)) + "$")
+}
+
+// Len returns the number of patterns.
+func (l IgnoreList) Len() int {
+	return len(l.rules)
+}
+
+// IgnoresType tells whether typeName is ignored.
+func (l IgnoreList) IgnoresType(typeName string) bool {
+	ignore := false
+	for _, rule := range l.rules {
+		if rule.field == nil && rule.pattern.MatchString(typeName) {
+			ignore = !rule.negate
+		}
+	}
+	return ignore
+}
+
+// IgnoresField tells whether the field fieldName of typeName is ignored.
+func (l IgnoreList) IgnoresField(typeName, fieldName string) bool {
+	ignore := false
+	for _, rule := range l.rules {
+		if rule.field != nil && rule.pattern.MatchString(typeName) && rule.field.MatchString(fieldName) {
+			ignore = !rule.negate
+		}
+	}
+	return ignore
+}
+
+func parseIgnoredFile(filePath string) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		panic("⚠️ Error reading ignored file" + filePath + ":" + err.Error())
+	}
+
+	ignored, err = ParseIgnoreList(string(content))
+	if err != nil {
+		fatalf("%s: %v", filePath, err)
+	}
+
+	fmt.Fprintln(banner, "ignored:", ignored.Len(), "patterns")
+	fmt.Fprintf(banner, "-------\n\n")
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// lintRules are the lint rule names with what they report, all enabled
+// unless turned off with --disable.
+var lintRules = map[string]string{
+	"anonymous-operation":      "operation without a name",
+	"unused-variable":          "variable defined but never used",
+	"unused-fragment":          "fragment never spread in the source",
+	"duplicate-operation-name": "operation name defined more than once in the source",
+	"list-without-id":          "list of objects having an id field selected without id",
+	"missing-limit":            "Hasura list root field queried without limit",
+	"max-depth":                "selection nested deeper than --max-depth",
+}
+
+// LintIssue is a lint rule violation of a UI operation or fragment.
+type LintIssue struct {
+	// Position is the host file location, path:line:col
+	Position string `json:"position"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+// Lint checks the operations and fragments of docs against the lint rules
+// not in disabled. Fragments spread from other documents are resolved, and
+// duplicate names and unused fragments are checked across all docs.
+func Lint(schema *ast.Schema, docs []GQLDocument, disabled map[string]bool, maxDepth int) []LintIssue {
+	var issues []LintIssue
+	add := func(rule, position, format string, args ...interface{}) {
+		if disabled[rule] {
+			return
+		}
+		issues = append(issues, LintIssue{Position: position, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	operations := operationIndex{}
+	fragments := map[string]string{}
+	spread := map[string]bool{}
+
+	for i, doc := range withFragments(docs) {
+		for _, match := range fragmentSpreadRegex.FindAllStringSubmatch(docs[i].Body, -1) {
+			spread[match[1]] = true
+		}
+
+		queryDoc, errs := ValidateQuery(schema, doc.Body)
+		if queryDoc == nil {
+			reportValidation(errs, doc)
+			continue
+		}
+		recordOperations(doc, queryDoc)
+
+		for _, frag := range queryDoc.Fragments {
+			if doc.owns(frag.Position) {
+				fragments[frag.Name] = doc.Position(frag.Position.Line, frag.Position.Column)
+			}
+		}
+
+		for _, op := range queryDoc.Operations {
+			if !doc.owns(op.Position) {
+				continue
+			}
+			position := doc.Position(op.Position.Line, op.Position.Column)
+
+			if op.Name == "" {
+				add("anonymous-operation", position, "anonymous %s", op.Operation)
+			}
+			operations.add(doc, op)
+
+			used := map[string]bool{}
+			for _, vd := range usedVariables(op, op.SelectionSet) {
+				used[vd.Variable] = true
+			}
+			for _, vd := range op.VariableDefinitions {
+				if !used[vd.Variable] {
+					add("unused-variable", doc.Position(vd.Position.Line, vd.Position.Column), "variable $%s is never used", vd.Variable)
+				}
+			}
+
+			if op.Operation != ast.Mutation {
+				for _, sel := range op.SelectionSet {
+					field, ok := sel.(*ast.Field)
+					if !ok || field.Definition == nil || field.Definition.Type.Elem == nil {
+						continue
+					}
+					if field.Definition.Arguments.ForName("limit") != nil && field.Arguments.ForName("limit") == nil {
+						add("missing-limit", doc.Position(field.Position.Line, field.Position.Column), "list %s queried without limit", field.Name)
+					}
+				}
+			}
+
+			if depth := selectionDepth(op.SelectionSet, map[string]bool{}); depth > maxDepth {
+				add("max-depth", position, "selection depth %d exceeds %d", depth, maxDepth)
+			}
+		}
+
+		for _, op := range queryDoc.Operations {
+			if doc.owns(op.Position) {
+				lintListIDs(schema, op.SelectionSet, doc, add)
+			}
+		}
+		for _, frag := range queryDoc.Fragments {
+			if doc.owns(frag.Position) {
+				lintListIDs(schema, frag.SelectionSet, doc, add)
+			}
+		}
+	}
+
+	for _, name := range operations.duplicates() {
+		defs := operations[name]
+		for _, def := range defs[1:] {
+			add("duplicate-operation-name", def.Position, "operation %s already defined at %s", name, defs[0].Position)
+		}
+	}
+	for name, position := range fragments {
+		if !spread[name] {
+			add("unused-fragment", position, "fragment %s is never spread", name)
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return positionLess(issues[i].Position, issues[j].Position)
+	})
+	return issues
+}
+
+// positionLess orders path:line:col positions by path, then by line and
+// column numerically.
+func positionLess(a, b string) bool {
+	aPath, aLine, aCol := splitPosition(a)
+	bPath, bLine, bCol := splitPosition(b)
+	if aPath != bPath {
+		return aPath < bPath
+	}
+	if aLine != bLine {
+		return aLine < bLine
+	}
+	return aCol < bCol
+}
+
+func splitPosition(position string) (path string, line, col int) {
+	path = position
+	if idx := strings.LastIndex(path, ":"); idx != -1 {
+		col, _ = strconv.Atoi(path[idx+1:])
+		path = path[:idx]
+	}
+	if idx := strings.LastIndex(path, ":"); idx != -1 {
+		line, _ = strconv.Atoi(path[idx+1:])
+		path = path[:idx]
+	}
+	return
+}
+
+// lintListIDs reports list fields of objects having an id field whose
+// selection, fragments included, lacks id.
+func lintListIDs(schema *ast.Schema, selectionSet ast.SelectionSet, doc GQLDocument, add func(rule, position, format string, args ...interface{})) {
+	for _, sel := range selectionSet {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if s.Definition != nil && s.Definition.Type.Elem != nil && len(s.SelectionSet) > 0 {
+				def := schema.Types[s.Definition.Type.Name()]
+				if def != nil && def.Fields.ForName("id") != nil && !selectsField(s.SelectionSet, "id", map[string]bool{}) {
+					add("list-without-id", doc.Position(s.Position.Line, s.Position.Column), "list %s selected without id", s.Name)
+				}
+			}
+			lintListIDs(schema, s.SelectionSet, doc, add)
+		case *ast.InlineFragment:
+			lintListIDs(schema, s.SelectionSet, doc, add)
+		}
+	}
+}
+
+// selectsField tells whether selectionSet selects the field name directly or
+// through its fragments.
+func selectsField(selectionSet ast.SelectionSet, name string, visited map[string]bool) bool {
+	for _, sel := range selectionSet {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if s.Name == name {
+				return true
+			}
+		case *ast.InlineFragment:
+			if selectsField(s.SelectionSet, name, visited) {
+				return true
+			}
+		case *ast.FragmentSpread:
+			if s.Definition != nil && !visited[s.Name] {
+				visited[s.Name] = true
+				if selectsField(s.Definition.SelectionSet, name, visited) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// selectionDepth returns the field nesting of selectionSet, fragments
+// expanded, a flat selection being 1 deep.
+func selectionDepth(selectionSet ast.SelectionSet, visited map[string]bool) int {
+	depth := 0
+	for _, sel := range selectionSet {
+		d := 0
+		switch s := sel.(type) {
+		case *ast.Field:
+			d = 1 + selectionDepth(s.SelectionSet, visited)
+		case *ast.InlineFragment:
+			d = selectionDepth(s.SelectionSet, visited)
+		case *ast.FragmentSpread:
+			if s.Definition != nil && !visited[s.Name] {
+				visited[s.Name] = true
+				d = selectionDepth(s.Definition.SelectionSet, visited)
+				delete(visited, s.Name)
+			}
+		}
+		if d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+func reportLint(schemaFilePath, sourcePath string, disable []string, maxDepth int) {
+	// Load schema
+	schema := LoadSchema(schemaFilePath)
+
+	disabled := map[string]bool{}
+	for _, rule := range disable {
+		if _, exist := lintRules[rule]; !exist {
+			names := make([]string, 0, len(lintRules))
+			for name := range lintRules {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fatalf("unknown lint rule %s, rules are %s", rule, strings.Join(names, ", "))
+		}
+		disabled[rule] = true
+	}
+
+	issues := Lint(schema, extractGQLFromPath(sourcePath), disabled, maxDepth)
+	recordResults(issues)
+
+	for _, issue := range issues {
+		fmt.Fprintf(stdout, "%s: %s: %s\n", issue.Position, issue.Rule, issue.Message)
+	}
+
+	fmt.Fprintf(stdout, "\n-------\n\n")
+	fmt.Fprintln(stdout, "lint:", len(issues), "issues")
+	if len(issues) > 0 && opts.Strict {
+		fatalf("%d lint issues", len(issues))
+	}
+}
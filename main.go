@@ -1,35 +1,58 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/jessevdk/go-flags"
 	"github.com/vektah/gqlparser/v2"
 	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 	"github.com/vektah/gqlparser/v2/parser"
 )
 
 var (
 	opts struct {
-		SchemaFile  string `long:"schema" description:"Input raw schema file"`
-		SourceFile  string `long:"source" description:"Input source file which countain graphql (.js,.ts)"`
-		FieldGQL    string `long:"field" description:"Input field GQL string"`
-		TypeGQL     string `long:"type" description:"Input type GQL string"`
-		Depth       uint   `short:"d" long:"depth" description:"Type recursion depth, default 5" default:"5"`
-		IgnoredFile string `short:"i" long:"ignored" description:"Type ignored file path"`
+		SchemaFile  string   `long:"schema" description:"Input raw schema file" json:"schema"`
+		SourceFile  string   `long:"source" description:"Input source file which countain graphql (.js,.ts), or a directory" json:"source"`
+		FieldGQL    string   `long:"field" description:"Input field GQL string, a plain field name for who-uses" json:"field"`
+		TypeGQL     string   `long:"type" description:"Input type GQL string" json:"type"`
+		Depth       uint     `short:"d" long:"depth" description:"Type recursion depth in levels, the printed type being level 1, default 5" default:"5" json:"depth"`
+		MaxTypes    uint     `long:"max-types" description:"Most types printed by --type and --field, unlimited when 0" json:"max_types"`
+		Fields      string   `long:"fields" description:"Fields of --type to keep, id,lot_number,product{sku,name} or an inline selection set" json:"fields"`
+		From        string   `long:"from" description:"Type the path command starts from" json:"from"`
+		To          string   `long:"to" description:"Type the path command leads to" json:"to"`
+		AllPaths    uint     `long:"all" description:"With path, list every path up to this many fields instead of the shortest ones" json:"all"`
+		GraphFormat string   `long:"format" description:"Graph format, dot or mermaid" default:"dot" json:"format"`
+		Skeleton    bool     `long:"skeleton" description:"With --field, print an operation document with variables for every argument and the scalar fields up to --depth" json:"skeleton"`
+		IgnoredFile string   `short:"i" long:"ignored" description:"Type ignored file path" json:"ignored"`
+		OutDir      string   `long:"out-dir" description:"Write emitted types into .graphqls files of this directory instead of stdout" json:"out_dir"`
+		Split       []string `long:"split" description:"Out dir file rule, prefix=file for type name prefix, source:path=file for source path or route:/path=file for the page route of the source, default stock_=inventory,outbound_=outbound,inboundv3_=inbound" json:"split"`
+		GqlgenFile  string   `long:"gqlgen" description:"Create or update the models section of this gqlgen.yml for emitted types" json:"gqlgen"`
+		ModelPkg    string   `long:"model-package" description:"Go package bound to emitted types in gqlgen.yml, scalars only when empty" json:"model_package"`
+		Resolvers   string   `long:"resolvers" description:"Append Hasura forwarding resolver stubs for the root fields to this Go file" json:"resolvers"`
+		ModelsFile  string   `long:"models" description:"Append Go structs for the emitted objects and inputs to this Go file" json:"models"`
+		RenamesFile string   `long:"renames" description:"Rename map file for rewrite, lines of field:old=new, arg:field.old=new or type:old=new" json:"renames"`
+		Initialisms []string `long:"initialism" description:"Extra Go initialism for generated names, e.g. sku, as go_initialisms in gqlgen.yml, may be repeated" json:"initialism"`
+		ScalarMap   []string `long:"scalar" description:"Scalar to Go type binding, scalar=go/pkg.Type, extends the uuid, timestamptz, json(b), numeric, bigint defaults" json:"scalar"`
+		Strict      bool     `long:"strict" description:"Fail the run when extracted operations are invalid against the schema" json:"strict"`
+		Check       bool     `long:"check" description:"With fmt, only report the files that are not formatted and fail if any" json:"check"`
+		Sort        bool     `long:"sort" description:"With fmt, sort the fields of each selection set by response name" json:"sort"`
+		Disable     []string `long:"disable" description:"Lint rule to turn off, may be repeated" json:"disable"`
+		MaxDepth    uint     `long:"max-depth" description:"Deepest selection allowed by lint, default 6" default:"6" json:"max_depth"`
+		Output      string   `short:"o" long:"output" description:"Output format, text or json" default:"text" json:"output"`
 	}
 
-	scalarUnq    map[string]bool = map[string]bool{}
-	ignoredTypes map[string]bool = map[string]bool{}
+	scalarUnq map[string]bool = map[string]bool{}
+	ignored   IgnoreList
 )
 
 func main() {
-	_, err := flags.Parse(&opts)
+	args, err := flags.Parse(&opts)
 	if err != nil {
 		// Check the specific error type
 		if flagsErr, ok := err.(*flags.Error); ok {
@@ -43,52 +66,114 @@ func main() {
 		os.Exit(1)
 	}
 
-	fmt.Println("schema file: ", opts.SchemaFile)
-	fmt.Println("source file: ", opts.SourceFile)
-	fmt.Println("field string: ", opts.FieldGQL)
-	fmt.Println("type string: ", opts.TypeGQL)
-	fmt.Println("depth uint: ", opts.Depth)
-	fmt.Println("ignored file: ", opts.IgnoredFile)
+	startReport(opts.Output)
+	defer func() {
+		// surface panics as errors of the json document
+		if r := recover(); r != nil {
+			if report == nil {
+				panic(r)
+			}
+			fatalf("%s", strings.TrimSpace(strings.TrimPrefix(fmt.Sprint(r), "⚠️ Error:")))
+		}
+	}()
+
+	// ts, graph and --skeleton print a file meant to be redirected
+	if report == nil && (opts.Skeleton || len(args) > 0 && (args[0] == "ts" || args[0] == "graph")) {
+		banner = os.Stderr
+	}
 
-	fmt.Printf("-------\n\n")
+	fmt.Fprintln(banner, "schema file: ", opts.SchemaFile)
+	fmt.Fprintln(banner, "source file: ", opts.SourceFile)
+	fmt.Fprintln(banner, "field string: ", opts.FieldGQL)
+	fmt.Fprintln(banner, "type string: ", opts.TypeGQL)
+	fmt.Fprintln(banner, "depth uint: ", opts.Depth)
+	fmt.Fprintln(banner, "max types uint: ", opts.MaxTypes)
+	fmt.Fprintln(banner, "fields string: ", opts.Fields)
+	fmt.Fprintln(banner, "ignored file: ", opts.IgnoredFile)
+	fmt.Fprintln(banner, "out dir: ", opts.OutDir)
+	fmt.Fprintln(banner, "gqlgen file: ", opts.GqlgenFile)
+	fmt.Fprintln(banner, "resolvers file: ", opts.Resolvers)
+	fmt.Fprintln(banner, "models file: ", opts.ModelsFile)
+	fmt.Fprintln(banner, "renames file: ", opts.RenamesFile)
+	fmt.Fprintln(banner, "strict bool: ", opts.Strict)
+	fmt.Fprintln(banner, "output string: ", opts.Output)
+
+	fmt.Fprintf(banner, "-------\n\n")
 
 	if opts.IgnoredFile != "" {
 		parseIgnoredFile(opts.IgnoredFile)
 	}
 
-	if opts.SourceFile != "" {
-		gqlQuery := extractGQLFromFile(opts.SourceFile)
-
-		if gqlQuery == "" {
-			fmt.Fprintln(os.Stderr, "⚠️ Error: no graphql query/mutation extracted")
-			os.Exit(1)
-		}
-
-		fetchByQuery(opts.SchemaFile, gqlQuery)
+	if len(args) > 0 {
+		runCommand(args[0], args[1:])
+	} else if opts.SourceFile != "" {
+		fetchBySource(opts.SchemaFile, opts.SourceFile)
 	} else if opts.FieldGQL != "" {
-		fetchByField(opts.SchemaFile, opts.FieldGQL, &opts.Depth)
+		fetchByField(opts.SchemaFile, opts.FieldGQL, opts.Depth)
 	} else if opts.TypeGQL != "" {
-		fetchByType(opts.SchemaFile, opts.TypeGQL, &opts.Depth)
+		fetchByType(opts.SchemaFile, opts.TypeGQL, opts.Depth)
 	}
+
+	flushReport()
 }
 
-func parseIgnoredFile(filePath string) {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		panic("⚠️ Error reading ignored file" + filePath + ":" + err.Error())
+// runCommand dispatches the report commands given as the first positional
+// argument, e.g. gqlsch deprecated --schema raw.graphql --source src/
+func runCommand(command string, args []string) {
+	switch command {
+	case "deprecated":
+		requireSource(command)
+		reportDeprecated(opts.SchemaFile, opts.SourceFile)
+	case "coverage":
+		requireSource(command)
+		reportCoverage(opts.SchemaFile, opts.SourceFile)
+	case "rewrite":
+		requireSource(command)
+		if opts.RenamesFile == "" {
+			fatalf("--renames is required for %s", command)
+		}
+		rewriteSources(opts.SourceFile, opts.RenamesFile)
+	case "fmt":
+		requireSource(command)
+		formatSources(opts.SourceFile, opts.Check, opts.Sort)
+	case "lint":
+		requireSource(command)
+		reportLint(opts.SchemaFile, opts.SourceFile, opts.Disable, int(opts.MaxDepth))
+	case "duplicates":
+		requireSource(command)
+		reportDuplicates(opts.SchemaFile, opts.SourceFile)
+	case "path":
+		if opts.From == "" || opts.To == "" {
+			fatalf("--from and --to are required for %s", command)
+		}
+		reportTypePaths(opts.SchemaFile, opts.From, opts.To, int(opts.AllPaths))
+	case "graph":
+		reportGraph(opts.SchemaFile, opts.SourceFile, opts.TypeGQL, opts.Depth, opts.GraphFormat)
+	case "explore":
+		exploreSchema(opts.SchemaFile)
+	case "search":
+		if len(args) != 1 {
+			fatalf("search takes one term, e.g. gqlsch search --schema schema.graphql stock_invetory")
+		}
+		reportSearch(opts.SchemaFile, args[0])
+	case "ts":
+		requireSource(command)
+		reportTypeScript(opts.SchemaFile, opts.SourceFile)
+	case "who-uses":
+		requireSource(command)
+		if opts.TypeGQL == "" {
+			fatalf("--type is required for %s", command)
+		}
+		reportWhoUses(opts.SchemaFile, opts.SourceFile, opts.TypeGQL, opts.FieldGQL)
+	default:
+		fatalf("unknown command %s", command)
 	}
+}
 
-	lines := strings.Split(string(content), "\n")
-	total := 0
-	for i, line := range lines {
-		ignoredTypes[line] = true
-		total = i
+func requireSource(command string) {
+	if opts.SourceFile == "" {
+		fatalf("--source file or directory is required for %s", command)
 	}
-
-	total++
-
-	fmt.Println("ignored:", total, "types")
-	fmt.Printf("-------\n\n")
 }
 
 func LoadSchema(schemaFilePath string) *ast.Schema {
@@ -104,109 +189,251 @@ func LoadSchema(schemaFilePath string) *ast.Schema {
 	return schema
 }
 
-func extractGQLFromFile(filePath string) (output string) {
-	// Read the file content
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		panic("⚠️ Error reading gql file" + filePath + ":" + err.Error())
-	}
+// fetchBySource prints the trimmed types selected by the operations of the
+// documents of sourcePath, once per source file.
+func fetchBySource(schemaFilePath, sourcePath string) {
+	// Load schema
+	schema := LoadSchema(schemaFilePath)
 
-	// Convert the content to a string
-	fileContent := string(content)
+	docs := extractGQLFromPath(sourcePath)
+	if len(docs) == 0 {
+		fatalf("no graphql query/mutation extracted")
+	}
 
-	// Define the regular expression pattern to find GraphQL queries
-	// This pattern looks for 'gql`' or 'graphql`' followed by any characters until a closing backtick.
-	// It also captures the content between the backticks.
-	re := regexp.MustCompile(`(?s)(?:gql|graphql)` + "`" + `(.*?)` + "`")
+	sources, operations, invalid := sourceOperations(schema, docs)
+	if invalid > 0 && opts.Strict {
+		fatalf("%d validation error(s) in %s", invalid, sourcePath)
+	}
 
-	// Find all matches in the file content
-	matches := re.FindAllStringSubmatch(fileContent, -1)
+	for _, source := range sources {
+		emitTypes(schema, trimmedOutputs(schema, operations[source]), source)
 
-	// Iterate through the matches and print the extracted queries
-	if len(matches) > 0 {
-		output = matches[0][1]
-	} else {
-		fmt.Println("No GraphQL queries found in the file.")
+		if opts.Resolvers != "" {
+			WriteResolvers(opts.Resolvers, schema, QueryResolverStubs(schema, operations[source]))
+		}
 	}
-	return
 }
 
-func fetchByQuery(schemaFilePath, gqlQuery string) {
-	// Load schema
-	schema := LoadSchema(schemaFilePath)
+// sourceOperations validates docs, fragments of other documents resolved,
+// and groups their own operations by source file. It reports the validation
+// errors and returns their number; a fragment spread by another document is
+// not unused.
+func sourceOperations(schema *ast.Schema, docs []GQLDocument) (sources []string, operations map[string]*ast.QueryDocument, invalid int) {
+	spread := map[string]bool{}
+	for _, doc := range docs {
+		for _, match := range fragmentSpreadRegex.FindAllStringSubmatch(doc.Body, -1) {
+			spread[match[1]] = true
+		}
+	}
 
-	// Load query
+	operations = map[string]*ast.QueryDocument{}
+	for _, doc := range withFragments(docs) {
+		queryDoc, errs := ValidateQuery(schema, doc.Body)
+		if queryDoc == nil {
+			invalid += reportValidation(errs, doc)
+			continue
+		}
+		recordOperations(doc, queryDoc)
 
-	queryDoc, err := parser.ParseQuery(&ast.Source{Input: gqlQuery, Name: "query.graphql"})
-	if err != nil {
-		panic(err)
+		var kept gqlerror.List
+		for _, e := range errs {
+			if e.Rule == "NoUnusedFragments" && len(e.Locations) > 0 {
+				if frag := fragmentAt(queryDoc, e.Locations[0]); frag != nil && spread[frag.Name] {
+					continue
+				}
+			}
+			kept = append(kept, e)
+		}
+		invalid += reportValidation(kept, doc)
+
+		if operations[doc.SourcePath] == nil {
+			sources = append(sources, doc.SourcePath)
+			operations[doc.SourcePath] = &ast.QueryDocument{}
+		}
+		for _, op := range queryDoc.Operations {
+			if doc.owns(op.Position) {
+				operations[doc.SourcePath].Operations = append(operations[doc.SourcePath].Operations, op)
+			}
+		}
+	}
+
+	return
+}
+
+// fragmentAt returns the fragment of queryDoc defined at location, if any.
+func fragmentAt(queryDoc *ast.QueryDocument, location gqlerror.Location) *ast.FragmentDefinition {
+	for _, frag := range queryDoc.Fragments {
+		if frag.Position.Line == location.Line && frag.Position.Column == location.Column {
+			return frag
+		}
 	}
+	return nil
+}
 
+// trimmedOutputs returns the trimmed types selected by the operations of
+// queryDoc, along with the types of their variables.
+func trimmedOutputs(schema *ast.Schema, queryDoc *ast.QueryDocument) []string {
 	visited := map[string]map[string]bool{}
 	var output []string
 
 	for _, op := range queryDoc.Operations {
+		label := operationLabel(op)
 		for _, vd := range op.VariableDefinitions {
-			d := schema.Types[vd.Type.NamedType]
+			d := schema.Types[vd.Type.Name()]
 			if d == nil || d.BuiltIn {
 				continue
 			}
+			if ignored.IgnoresType(d.Name) {
+				warnf("%s: variable $%s is of ignored type %s, the output is incomplete", label, vd.Variable, d.Name)
+				continue
+			}
 
 			output = append(output, processArgument(d))
 		}
+		root := operationRoot(schema, op)
+		if root == nil {
+			warnf("%s: the schema has no %s root, skipped", label, op.Operation)
+			continue
+		}
 		for _, sel := range op.SelectionSet {
 			if field, ok := sel.(*ast.Field); ok {
-				if op.Operation == "query" {
-					processField(field, schema.Query, schema, visited, &output)
-				} else if op.Operation == "mutation" {
-					processField(field, schema.Mutation, schema, visited, &output)
-				} else {
-					panic("⚠️ Error: gql operation type is not supported: " + op.Operation)
-				}
+				processField(field, root, schema, visited, &output, label, "")
 			}
 		}
 	}
 
-	for _, o := range output {
-		fmt.Println(o)
+	return output
+}
+
+// operationLabel names op in warnings, e.g. query GetInbound.
+func operationLabel(op *ast.OperationDefinition) string {
+	if op.Name == "" {
+		return string(op.Operation) + " <anonymous>"
 	}
+	return string(op.Operation) + " " + op.Name
 }
 
-func fetchByType(schemaFilePath, gqlType string, depth *uint) {
+// operationRoot returns the schema root type of an operation, nil when the
+// schema has no root for its operation type
+func operationRoot(schema *ast.Schema, op *ast.OperationDefinition) *ast.Definition {
+	switch op.Operation {
+	case ast.Query:
+		return schema.Query
+	case ast.Mutation:
+		return schema.Mutation
+	case ast.Subscription:
+		return schema.Subscription
+	}
+	return nil
+}
+
+func fetchByType(schemaFilePath, gqlType string, depth uint) {
 	// Load schema
 	schema := LoadSchema(schemaFilePath)
 	visited := map[string]bool{}
 	outputs := []string{}
 
-	printSchemaField(schema, gqlType, visited, &outputs, depth)
+	if opts.Fields != "" {
+		typeDef := schema.Types[gqlType]
+		if typeDef == nil {
+			fatalf("type %s not found in schema%s", gqlType, didYouMean(gqlType, typeNames(schema)))
+		}
+		if typeDef.Kind != ast.Object && typeDef.Kind != ast.Interface {
+			fatalf("--fields needs an object type, %s is not one", gqlType)
+		}
+		selectionSet, err := parseFieldSelection(opts.Fields)
+		if err == nil {
+			err = checkSelection(schema, typeDef, selectionSet)
+		}
+		if err != nil {
+			fatalf("%v", err)
+		}
+		processSelectionSet(selectionSet, typeDef, schema, map[string]map[string]bool{}, &outputs, "type "+gqlType, "")
+	} else {
+		printSchemaField(schema, gqlType, visited, &outputs, depth)
+	}
 
-	for _, output := range outputs {
-		fmt.Println(output)
+	emitTypes(schema, outputs, "")
+}
+
+// parseFieldSelection parses --fields, either id,lot_number,product{sku,name}
+// or an inline selection set such as { id lot_number product { sku } }.
+func parseFieldSelection(fields string) (ast.SelectionSet, error) {
+	fields = strings.TrimSpace(fields)
+	if !strings.HasPrefix(fields, "{") {
+		// commas are insignificant in GraphQL, the mini syntax is a selection set
+		fields = "{" + fields + "}"
+	}
+
+	queryDoc, err := parser.ParseQuery(&ast.Source{Input: fields, Name: "--fields"})
+	if err != nil {
+		return nil, fmt.Errorf("invalid --fields %s: %v", fields, err)
+	}
+	if len(queryDoc.Operations) != 1 {
+		return nil, fmt.Errorf("invalid --fields %s: a single selection set is expected", fields)
 	}
+	return queryDoc.Operations[0].SelectionSet, nil
 }
 
-func fetchByField(schemaFilePath, gqlField string, depth *uint) {
+// checkSelection rejects fields unknown to def and selections of leaf
+// fields, as no validator runs for --fields.
+func checkSelection(schema *ast.Schema, def *ast.Definition, selectionSet ast.SelectionSet) error {
+	for _, sel := range selectionSet {
+		field, ok := sel.(*ast.Field)
+		if !ok {
+			return fmt.Errorf("--fields only accepts fields, not fragments")
+		}
+		fieldDef := def.Fields.ForName(field.Name)
+		if fieldDef == nil {
+			return fmt.Errorf("--fields: unknown field %s on type %s%s", field.Name, def.Name, didYouMean(field.Name, fieldNames(def)))
+		}
+		typeDef := schema.Types[fieldDef.Type.Name()]
+		if len(field.SelectionSet) > 0 {
+			if typeDef.Kind != ast.Object && typeDef.Kind != ast.Interface {
+				return fmt.Errorf("--fields: %s.%s is a leaf, it has no fields to select", def.Name, field.Name)
+			}
+			if err := checkSelection(schema, typeDef, field.SelectionSet); err != nil {
+				return err
+			}
+		} else if typeDef.Kind == ast.Object || typeDef.Kind == ast.Interface {
+			return fmt.Errorf("--fields: %s.%s needs a selection, e.g. %s{id}", def.Name, field.Name, field.Name)
+		}
+	}
+	return nil
+}
+
+func fetchByField(schemaFilePath, gqlField string, depth uint) {
 	// Load schema
 	schema := LoadSchema(schemaFilePath)
 
 	fields := strings.Split(gqlField, " ")
 	if len(fields) != 2 {
-		fmt.Println("⚠️ Error: the format must be query/mutation field_name, example: mutation create_job")
-		return
+		fatalf("the format must be query/mutation field_name, example: mutation create_job")
 	}
 	opType := fields[0]
 	opName := fields[1]
 
-	var fieldDef *ast.FieldDefinition
+	var root *ast.Definition
 	if opType == "query" {
-		fieldDef = schema.Types[schema.Query.Name].Fields.ForName(opName)
+		root = schema.Query
 	} else if opType == "mutation" {
-		fieldDef = schema.Types[schema.Mutation.Name].Fields.ForName(opName)
+		root = schema.Mutation
 	} else {
-		panic("⚠️ Error: gql operation type is not supported: " + opType)
+		fatalf("gql operation type is not supported: %s, use query or mutation", opType)
+	}
+	if root == nil {
+		fatalf("the schema has no %s root", opType)
 	}
 
+	fieldDef := root.Fields.ForName(opName)
 	if fieldDef == nil {
+		fatalf("%s field %s not found in schema%s", opType, opName, didYouMean(opName, fieldNames(root)))
+	}
+
+	if opts.Skeleton {
+		doc := skeletonDocument(schema, ast.Operation(opType), fieldDef, depth)
+		recordResults(doc)
+		fmt.Fprint(stdout, doc)
 		return
 	}
 
@@ -214,12 +441,17 @@ func fetchByField(schemaFilePath, gqlField string, depth *uint) {
 	outputs := []string{}
 
 	// Print field info
-	fmt.Printf("Field Name: %s\n", fieldDef.Name)
-	fmt.Printf("Type: %s\n", fieldDef.Type.String())
-	fmt.Printf("Description: %s\n", fieldDef.Description)
-	fmt.Println("Arguments:")
+	info := jsonField{Name: fieldDef.Name, Type: fieldDef.Type.String(), Description: fieldDef.Description}
+	for _, arg := range fieldDef.Arguments {
+		info.Args = append(info.Args, jsonArg{Name: arg.Name, Type: arg.Type.String()})
+	}
+	recordResults(info)
+	fmt.Fprintf(stdout, "Field Name: %s\n", fieldDef.Name)
+	fmt.Fprintf(stdout, "Type: %s\n", fieldDef.Type.String())
+	fmt.Fprintf(stdout, "Description: %s\n", fieldDef.Description)
+	fmt.Fprintln(stdout, "Arguments:")
 	for _, arg := range fieldDef.Arguments {
-		fmt.Printf("- %s: %s\n", arg.Name, arg.Type.String())
+		fmt.Fprintf(stdout, "- %s: %s\n", arg.Name, arg.Type.String())
 		argBase := arg.Type.Name()
 		if isCustomType(argBase) {
 			printSchemaField(schema, argBase, visited, &outputs, depth)
@@ -230,41 +462,100 @@ func fetchByField(schemaFilePath, gqlField string, depth *uint) {
 		printSchemaField(schema, fieldDef.Type.Name(), visited, &outputs, depth)
 	}
 
-	fmt.Printf("\n-------\n\n")
+	fmt.Fprintf(stdout, "\n-------\n\n")
 
-	for _, def := range outputs {
-		fmt.Println(def)
+	emitTypes(schema, outputs, "")
+
+	if opts.Resolvers != "" {
+		WriteResolvers(opts.Resolvers, schema, []ResolverStub{{
+			Operation:    ast.Operation(opType),
+			Field:        fieldDef,
+			SelectionSet: scalarSelection(schema, schema.Types[fieldDef.Type.Name()], 1),
+		}})
 	}
 }
 
-// Recursive field processor
-func processField(field *ast.Field, parentType *ast.Definition, schema *ast.Schema, visited map[string]map[string]bool, output *[]string) {
+// skeletonDocument builds the --skeleton operation of the root field fieldDef:
+// a variable per argument and the scalar fields up to depth levels.
+func skeletonDocument(schema *ast.Schema, operation ast.Operation, fieldDef *ast.FieldDefinition, depth uint) string {
+	return fieldDocument(ResolverStub{
+		Operation:    operation,
+		Field:        fieldDef,
+		SelectionSet: scalarSelection(schema, schema.Types[fieldDef.Type.Name()], depth),
+	}, goName(fieldDef.Name))
+}
+
+// Recursive field processor, op and path of the parent field locating the
+// warnings about ignored selections
+func processField(field *ast.Field, parentType *ast.Definition, schema *ast.Schema, visited map[string]map[string]bool, output *[]string, op, path string) {
 	fieldDef := schema.Types[parentType.Name].Fields.ForName(field.Name)
 	if fieldDef == nil {
 		return
 	}
 
+	if path != "" {
+		path += "."
+	}
+	path += field.Name
+	if ignored.IgnoresField(parentType.Name, field.Name) {
+		warnf("%s: %s selects ignored field %s.%s, the output is incomplete", op, path, parentType.Name, field.Name)
+		return
+	}
+
 	fieldType := unwrapType(fieldDef.Type)
 	typeDef := schema.Types[fieldType]
 
 	if typeDef == nil || typeDef.BuiltIn {
 		return
 	}
+	if ignored.IgnoresType(typeDef.Name) {
+		warnf("%s: %s selects ignored type %s, the output is incomplete", op, path, typeDef.Name)
+		return
+	}
+
+	processSelectionSet(field.SelectionSet, typeDef, schema, visited, output, op, path)
+}
 
+// processSelectionSet records the fields of selectionSet on typeDef, processes
+// them recursively and adds the trimmed typeDef to output. Fragments on
+// typeDef are merged into it, fragments on other types processed on their
+// own; spreads need the Definition set by validation.
+func processSelectionSet(selectionSet ast.SelectionSet, typeDef *ast.Definition, schema *ast.Schema, visited map[string]map[string]bool, output *[]string, op, path string) {
 	if visited[typeDef.Name] == nil {
 		visited[typeDef.Name] = map[string]bool{}
 	}
 
 	fieldHasArgs := map[string]bool{}
-	for _, sel := range field.SelectionSet {
-		if f, ok := sel.(*ast.Field); ok {
-			visited[typeDef.Name][f.Name] = true
-			if len(f.Arguments) > 0 {
-				fieldHasArgs[f.Name] = true
+	spread := map[string]bool{}
+	var walk func(ast.SelectionSet)
+	fragment := func(typeCondition string, selectionSet ast.SelectionSet) {
+		if typeCondition == "" || typeCondition == typeDef.Name {
+			walk(selectionSet)
+		} else if def := schema.Types[typeCondition]; def != nil && !ignored.IgnoresType(def.Name) {
+			processSelectionSet(selectionSet, def, schema, visited, output, op, path)
+		}
+	}
+	walk = func(selectionSet ast.SelectionSet) {
+		for _, sel := range selectionSet {
+			switch s := sel.(type) {
+			case *ast.Field:
+				visited[typeDef.Name][s.Name] = true
+				if len(s.Arguments) > 0 {
+					fieldHasArgs[s.Name] = true
+				}
+				processField(s, typeDef, schema, visited, output, op, path)
+			case *ast.InlineFragment:
+				fragment(s.TypeCondition, s.SelectionSet)
+			case *ast.FragmentSpread:
+				if s.Definition == nil || spread[s.Name] {
+					continue
+				}
+				spread[s.Name] = true
+				fragment(s.Definition.TypeCondition, s.Definition.SelectionSet)
 			}
-			processField(f, typeDef, schema, visited, output)
 		}
 	}
+	walk(selectionSet)
 
 	processArgumentList(fieldHasArgs, typeDef, schema, output)
 
@@ -280,7 +571,7 @@ func processArgumentList(fieldHasArgs map[string]bool, def *ast.Definition, sche
 			for _, a := range f.Arguments {
 				t := unwrapType(a.Type)
 				d := schema.Types[t]
-				if d == nil || d.BuiltIn {
+				if d == nil || d.BuiltIn || ignored.IgnoresType(d.Name) {
 					continue
 				}
 				if !typeAlreadyAdded(d.Name, *output) {
@@ -319,7 +610,7 @@ func unwrapType(t *ast.Type) string {
 
 func buildPartialType(def *ast.Definition, fields map[string]bool) string {
 	var sb strings.Builder
-	if def.Name == "uuid" || def.Name == "json" || def.Name == "timestamptz" {
+	if def.Kind == ast.Scalar {
 		if _, exist := scalarUnq[def.Name]; exist {
 			return ""
 		}
@@ -327,9 +618,21 @@ func buildPartialType(def *ast.Definition, fields map[string]bool) string {
 		scalarUnq[def.Name] = true
 		return "scalar " + def.Name
 	}
-	sb.WriteString("type " + def.Name + " {\n")
+	if def.Kind == ast.Enum {
+		sb.WriteString("enum " + def.Name + " {\n")
+		for _, v := range def.EnumValues {
+			sb.WriteString("  " + v.Name + "\n")
+		}
+		sb.WriteString("}")
+		return sb.String()
+	}
+	if def.Kind == ast.InputObject {
+		sb.WriteString("input " + def.Name + " {\n")
+	} else {
+		sb.WriteString("type " + def.Name + " {\n")
+	}
 	for _, f := range def.Fields {
-		if fields[f.Name] {
+		if fields[f.Name] && !ignored.IgnoresField(def.Name, f.Name) {
 			argStr := ""
 			if len(f.Arguments) > 0 {
 				var argStrList []string
@@ -359,73 +662,118 @@ func isCustomType(typeName string) bool {
 	return !builtInScalars[typeName] && !strings.HasPrefix(typeName, "__")
 }
 
-// printSchemaField prints a type definition and recursively prints nested types
-func printSchemaField(schema *ast.Schema, typeName string, visited map[string]bool, outputs *[]string, depth *uint) {
-	if _, exist := ignoredTypes[typeName]; exist {
+// printSchemaField prints a type definition and its nested types up to depth
+// levels, breadth first so each type is reached through its shortest path.
+// Fields whose type is cut off by --depth or --max-types are marked with a
+// trailing comment.
+func printSchemaField(schema *ast.Schema, typeName string, visited map[string]bool, outputs *[]string, depth uint) {
+	if ignored.IgnoresType(typeName) || visited[typeName] || depth == 0 {
 		return
 	}
-
-	if *depth > 0 {
-		*depth--
-	} else {
+	if opts.MaxTypes > 0 && uint(len(visited)) >= opts.MaxTypes {
+		warnf("--max-types %d reached, %s not printed", opts.MaxTypes, typeName)
 		return
 	}
-	if visited[typeName] {
-		return
+	if schema.Types[typeName] == nil {
+		fatalf("type %s not found in schema%s", typeName, didYouMean(typeName, typeNames(schema)))
 	}
-	visited[typeName] = true
 
-	typ := schema.Types[typeName]
-	if typ == nil {
-		panic("⚠️ Error: Type " + typeName + " not found in schema\n")
+	type pending struct {
+		name  string
+		level uint
 	}
+	// visited holds the printed and queued types
+	visited[typeName] = true
+	queue := []pending{{name: typeName, level: 1}}
 
-	var sb strings.Builder
-	switch typ.Kind {
-	case ast.InputObject:
-		sb.WriteString("input " + typ.Name + " {\n")
-		for _, f := range typ.Fields {
-			sb.WriteString("  " + f.Name + ": " + f.Type.String() + "\n")
+	// follow queues nestedType, or notes why it is cut off
+	follow := func(sb *strings.Builder, nestedType string, level uint) {
+		if !isCustomType(nestedType) || visited[nestedType] || ignored.IgnoresType(nestedType) {
+			return
 		}
-		sb.WriteString("}")
-		for _, f := range typ.Fields {
-			nestedType := f.Type.Name()
-			if isCustomType(nestedType) {
-				printSchemaField(schema, nestedType, visited, outputs, depth)
-			}
-		}
-	case ast.Object:
-		sb.WriteString("type " + typ.Name + " {\n")
-		for _, f := range typ.Fields {
-			sb.WriteString("  " + f.Name + ": " + f.Type.String() + "\n")
+		if level >= depth {
+			sb.WriteString(" # truncated by --depth " + strconv.FormatUint(uint64(depth), 10))
+		} else if opts.MaxTypes > 0 && uint(len(visited)) >= opts.MaxTypes {
+			sb.WriteString(" # truncated by --max-types " + strconv.FormatUint(uint64(opts.MaxTypes), 10))
+		} else {
+			visited[nestedType] = true
+			queue = append(queue, pending{name: nestedType, level: level + 1})
 		}
-		sb.WriteString("}")
-		for _, f := range typ.Fields {
-			nestedType := f.Type.Name()
-			if isCustomType(nestedType) {
-				printSchemaField(schema, nestedType, visited, outputs, depth)
+	}
+	fieldLines := func(sb *strings.Builder, fields ast.FieldList, level uint, followTypes bool) {
+		for _, f := range fields {
+			sb.WriteString("  " + f.Name + ": " + f.Type.String())
+			if followTypes {
+				follow(sb, f.Type.Name(), level)
 			}
+			sb.WriteString("\n")
 		}
-	case ast.Enum:
-		sb.WriteString(fmt.Sprintf("enum %s: %v\n", typ.Name, typ.EnumValues))
-	case ast.Interface:
-		sb.WriteString("interface " + typ.Name + " {\n")
-		for _, f := range typ.Fields {
-			fmt.Printf("  %s: %s\n", f.Name, f.Type.String())
+	}
+
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		typ := schema.Types[p.name]
+
+		var sb strings.Builder
+		switch typ.Kind {
+		case ast.InputObject:
+			sb.WriteString("input " + typ.Name + " {\n")
+			fieldLines(&sb, keptFields(typ), p.level, true)
+			sb.WriteString("}")
+		case ast.Object:
+			sb.WriteString("type " + typ.Name + " {\n")
+			fieldLines(&sb, keptFields(typ), p.level, true)
+			sb.WriteString("}")
+		case ast.Enum:
+			sb.WriteString("enum " + typ.Name + " {\n")
+			for _, v := range typ.EnumValues {
+				sb.WriteString("  " + v.Name + "\n")
+			}
+			sb.WriteString("}")
+		case ast.Interface:
+			sb.WriteString("interface " + typ.Name + " {\n")
+			fieldLines(&sb, keptFields(typ), p.level, false)
+			sb.WriteString("}")
+		case ast.Union:
+			// a member per line, as fields, to carry its truncated comment
+			sb.WriteString("union " + typ.Name + " =")
+			members := 0
+			for _, member := range typ.Types {
+				if ignored.IgnoresType(member) {
+					continue
+				}
+				sb.WriteString("\n  | " + member)
+				follow(&sb, member, p.level)
+				members++
+			}
+			if members == 0 {
+				warnf("every member of union %s is ignored, not printed", typ.Name)
+				continue
+			}
+		case ast.Scalar:
+			sb.WriteString("scalar " + typ.Name)
+		default:
+			panic("⚠️ Error: Unknown type kind " + string(typ.Kind) + " for type " + typ.Name + "\n")
 		}
-		sb.WriteString("}")
-	case ast.Scalar:
-		sb.WriteString("scalar: " + typ.Name + "\n")
-	default:
-		panic("⚠️ Error: Unknown type kind " + string(typ.Kind) + " for type " + typ.Name + "\n")
+
+		*outputs = append(*outputs, sb.String())
 	}
+}
 
-	*outputs = append(*outputs, sb.String())
+// keptFields returns the fields of def not dropped by the ignored file.
+func keptFields(def *ast.Definition) (fields ast.FieldList) {
+	for _, f := range def.Fields {
+		if !ignored.IgnoresField(def.Name, f.Name) {
+			fields = append(fields, f)
+		}
+	}
+	return
 }
 
 func typeAlreadyAdded(name string, output []string) bool {
 	for _, o := range output {
-		if strings.HasPrefix(o, "type "+name+" ") {
+		if strings.HasPrefix(o, "type "+name+" ") || strings.HasPrefix(o, "input "+name+" ") || strings.HasPrefix(o, "enum "+name+" ") {
 			return true
 		}
 	}
@@ -437,15 +785,57 @@ type ImportResult struct {
 	FromPath string
 }
 
+var (
+	// Regular expression to match import statements with destructuring
+	importRegex = regexp.MustCompile(`import\s*(?:type\s*)?{([^}]+)}\s*from\s*['"]([^'"]+)['"]`)
+	// Regex for single import statements
+	singleImportRegex = regexp.MustCompile(`import\s+(?:type\s+)?{?\s*(\w+)\s*}?\s*from\s*['"]([^'"]+)['"]`)
+	// Regex for namespace imports, import * as Q from
+	namespaceImportRegex = regexp.MustCompile(`import\s+(?:type\s+)?\*\s*as\s+(\w+)\s*from\s*['"]([^'"]+)['"]`)
+)
+
+// importsFrom returns the names content imports from the modules starting
+// with pkg, such as @wms/hooks/, aliases resolved to the exported name. A
+// namespace import is named after its alias.
+func importsFrom(content, pkg string) (results []ImportResult) {
+	unq := map[string]bool{}
+	add := func(name, fromPath string) {
+		if asIdx := strings.Index(name, " as "); asIdx != -1 {
+			name = strings.TrimSpace(name[:asIdx])
+		}
+		name = strings.TrimSpace(strings.TrimPrefix(name, "type "))
+		if name == "" || !strings.HasPrefix(fromPath, pkg) || unq[name] {
+			return
+		}
+		unq[name] = true
+		results = append(results, ImportResult{Name: name, FromPath: fromPath})
+	}
+
+	// Find all destructured imports
+	for _, match := range importRegex.FindAllStringSubmatch(content, -1) {
+		for _, imp := range strings.Split(match[1], ",") {
+			// Clean up the import name
+			add(strings.Trim(strings.TrimSpace(imp), "{}"), match[2])
+		}
+	}
+
+	// Find all single imports
+	for _, match := range singleImportRegex.FindAllStringSubmatch(content, -1) {
+		add(strings.TrimSpace(match[1]), match[2])
+	}
+
+	// Find all namespace imports
+	for _, match := range namespaceImportRegex.FindAllStringSubmatch(content, -1) {
+		add(match[1], match[2])
+	}
+
+	return
+}
+
 func GetImportFromDir(directoryPath string) []ImportResult {
 	var results []ImportResult
 	unq := map[string]bool{}
 
-	// Regular expression to match import statements with destructuring
-	importRegex := regexp.MustCompile(`import\s*{([^}]+)}\s*from\s*['"](@wms/hooks/[^'"]+)['"]`)
-	// Regex for single import statements
-	singleImportRegex := regexp.MustCompile(`import\s+{?\s*(\w+)\s*}?\s*from\s*['"](@wms/hooks/[^'"]+)['"]`)
-
 	err := filepath.Walk(directoryPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -478,48 +868,13 @@ func GetImportFromDir(directoryPath string) []ImportResult {
 			return nil
 		}
 
-		fileContent := string(content)
-
-		// Find all destructured imports
-		matches := importRegex.FindAllStringSubmatch(fileContent, -1)
-		for _, match := range matches {
-			if len(match) >= 3 {
-				imports := strings.Split(match[1], ",")
-				fromPath := strings.TrimPrefix(match[2], "@")
-
-				for _, imp := range imports {
-					// Clean up the import name
-					name := strings.TrimSpace(imp)
-					name = strings.Trim(name, "{}")
-					if name == "" {
-						continue
-					}
-
-					if _, exist := unq[name]; !exist {
-						unq[name] = true
-						results = append(results, ImportResult{
-							Name:     name,
-							FromPath: fromPath,
-						})
-					}
-				}
-			}
-		}
-
-		// Find all single imports
-		singleMatches := singleImportRegex.FindAllStringSubmatch(fileContent, -1)
-		for _, match := range singleMatches {
-			if len(match) >= 3 {
-				name := strings.TrimSpace(match[1])
-				fromPath := strings.TrimPrefix(match[2], "@")
-
-				if _, exist := unq[name]; !exist {
-					unq[name] = true
-					results = append(results, ImportResult{
-						Name:     name,
-						FromPath: fromPath,
-					})
-				}
+		for _, imp := range importsFrom(string(content), "@wms/hooks/") {
+			if _, exist := unq[imp.Name]; !exist {
+				unq[imp.Name] = true
+				results = append(results, ImportResult{
+					Name:     imp.Name,
+					FromPath: strings.TrimPrefix(imp.FromPath, "@"),
+				})
 			}
 		}
 
@@ -533,10 +888,11 @@ func GetImportFromDir(directoryPath string) []ImportResult {
 	return results
 }
 
+// Regex for eligigle route, the path: of a page index.ts
+var routeRegex = regexp.MustCompile(`path:\s*['"](/[a-zA-Z-]+(?:/[a-zA-Z-]+)*)['"]`)
+
 func GetEligiblePage(directoryPath string) map[string]bool {
 	unqEligiblePage := map[string]bool{}
-	// Regex for eligigle route
-	routeRegex := regexp.MustCompile(`path:\s*['"](/[a-zA-Z-]+(?:/[a-zA-Z-]+)*)['"]`)
 
 	var eligibleRoute map[string]bool = map[string]bool{
 		"/inbound-v3/schedule":                    true,
@@ -626,7 +982,7 @@ func GetEligiblePage(directoryPath string) map[string]bool {
 		for _, match := range routeMatches {
 			if len(match) == 2 {
 				if _, exist := eligibleRoute[match[1]]; !exist {
-					fmt.Println("[DEBUG] invalid route", path, match[1])
+					fmt.Fprintln(stdout, "[DEBUG] invalid route", path, match[1])
 					return nil
 				}
 			}
@@ -1097,30 +1453,17 @@ func GetGQLImport(hooksParentFolderPath string) []string {
 		}
 
 		// Read file content
-		file, err := os.Open(path)
+		content, err := os.ReadFile(path)
 		if err != nil {
 			return err
 		}
-		defer file.Close()
-
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := scanner.Text()
-			// Look for import statements containing @wms/gqls
-			if strings.Contains(line, "import") && strings.Contains(line, "@wms/gqls") {
-				// Extract the import path
-				if start := strings.Index(line, "@wms/gqls"); start != -1 {
-					importPath := line[start:]
-					if end := strings.Index(importPath, "'"); end != -1 {
-						gqlImports[importPath[:end]] = true
-					} else if end := strings.Index(importPath, "\""); end != -1 {
-						gqlImports[importPath[:end]] = true
-					}
-				}
-			}
+
+		// Look for import statements from @wms/gqls
+		for _, imp := range importsFrom(string(content), "@wms/gqls") {
+			gqlImports[imp.FromPath] = true
 		}
 
-		return scanner.Err()
+		return nil
 	})
 
 	// Convert map to slice
@@ -2,10 +2,17 @@ package main_test
 
 import (
 	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	main "github.com/toshim45/gqlsch"
+	"github.com/vektah/gqlparser/v2/ast"
 )
 
 const (
@@ -56,6 +63,26 @@ func TestGetGQLImport(t *testing.T) {
 	t.Log("---done---")
 }
 
+func TestGetGQLImportStatements(t *testing.T) {
+	t.Log("---start---")
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"useCreateUserV2.ts": "import { CreateUser } from '@wms/gqls/user/create'\n" +
+			"import * as Q from '@wms/gqls/user/queries'\n" +
+			"import type { UserRole, type UserStatus } from '@wms/gqls/user/types'\n" +
+			"import { useMutation } from '@apollo/client'\n",
+	})
+
+	results := main.GetGQLImport(dir)
+	sort.Strings(results)
+	t.Log(results)
+	expected := []string{"@wms/gqls/user/create", "@wms/gqls/user/queries", "@wms/gqls/user/types"}
+	if strings.Join(results, ",") != strings.Join(expected, ",") {
+		t.Error("expected ", expected, " got ", results)
+	}
+	t.Log("---done---")
+}
+
 func getSuffixPath() string {
 	//TODO: this function only required for TestGetGQLImport
 	panic("unimplemented")
@@ -71,6 +98,483 @@ func TestParser(t *testing.T) {
 	t.Log("---done---")
 }
 
+func TestValidateQuery(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+
+	_, errs := main.ValidateQuery(schema, `query { create_inboundv3_inbound { id inb_type { name } } }`)
+	if len(errs) != 0 {
+		t.Error("unexpected errors: ", errs)
+	}
+
+	_, errs = main.ValidateQuery(schema, `query ($in: InboundV3Input) { create_inboundv3_inbound(in: $in, x: $y) { id lot_number } }`)
+	if len(errs) != 3 {
+		t.Error("expected unknown field, unknown argument and undefined variable, got: ", errs)
+	}
+
+	queryDoc, errs := main.ValidateQuery(schema, `query {`)
+	if queryDoc != nil || len(errs) != 1 {
+		t.Error("expected parse error, got: ", errs)
+	}
+	t.Log("---done---")
+}
+
+func TestGQLDocumentPosition(t *testing.T) {
+	t.Log("---start---")
+	doc := main.GQLDocument{SourcePath: "src/hooks/useX.ts", Line: 3, Column: 18}
+	if pos := doc.Position(1, 3); pos != "src/hooks/useX.ts:3:20" {
+		t.Error("unexpected first line position: " + pos)
+	}
+	if pos := doc.Position(4, 7); pos != "src/hooks/useX.ts:6:7" {
+		t.Error("unexpected position: " + pos)
+	}
+	t.Log("---done---")
+}
+
+func TestFindDeprecatedUsages(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	docs := []main.GQLDocument{{
+		SourcePath: "useDep.ts",
+		Line:       1,
+		Column:     1,
+		Body:       `query { create_inboundv3_inbound(in: { inb_type: "x", status: CLOSED }) { id code } }`,
+	}}
+
+	usages := main.FindDeprecatedUsages(schema, docs)
+	if len(usages) != 2 {
+		t.Fatal("expected deprecated field and enum value, got: ", usages)
+	}
+	for _, u := range usages {
+		t.Log(u.Position, u.Kind, u.Name, u.Reason)
+	}
+	if usages[1].Name != "InboundV3Inbound.code" || usages[1].Reason != "use id" {
+		t.Error("unexpected deprecated field: ", usages[1])
+	}
+	t.Log("---done---")
+}
+
+func TestComputeCoverage(t *testing.T) {
+	t.Log("---start---")
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"gqls/a.ts":         "export const GET_A = gql`\n  query GetA { create_inboundv3_inbound { id inb_type { name } } }\n`;\n",
+		"gqls/b.ts":         "export const GET_B = gql`\n  query GetB { create_inboundv3_inbound { id ...StatusFields } }\n  ${STATUS_FRAG}\n`;\n",
+		"gqls/fragments.ts": "export const STATUS_FRAG = gql`\n  fragment StatusFields on InboundV3Inbound { status }\n`;\n",
+		"gqls/sub.ts":       "export const ON_A = gql`\n  subscription OnA { create_inboundv3_inbound { code } }\n`;\n",
+		"hooks/useA.ts":     "import { GET_A } from '@wms/gqls/a';\nexport const useA = () => useQuery(GET_A);\n",
+		"pages/A/index.tsx": "import { useA } from '@wms/hooks/queries/useA';\n",
+	})
+
+	schema := main.LoadSchema("mini.graphql")
+	for _, c := range main.ComputeCoverage(schema, dir) {
+		t.Log(c.Name, len(c.Fields), "/", c.Total, c.Hooks, c.Pages)
+		switch c.Name {
+		case "InboundV3Inbound":
+			if len(c.Fields) != 3 || c.Total != 5 || len(c.Fields["id"]) != 2 || len(c.Fields["status"]) != 1 {
+				t.Error("unexpected coverage: ", c)
+			}
+			if len(c.Hooks["inb_type"]) != 1 || len(c.Pages["inb_type"]) != 1 || len(c.Hooks["status"]) != 0 {
+				t.Error("unexpected hooks/pages: ", c.Hooks, c.Pages)
+			}
+		case "query_root":
+			if c.Total != 1 || len(c.Fields["create_inboundv3_inbound"]) != 2 {
+				t.Error("unexpected root coverage: ", c)
+			}
+		}
+	}
+	t.Log("---done---")
+}
+
+func TestWhoUses(t *testing.T) {
+	t.Log("---start---")
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"gqls/inbound.ts":          "export const GET_INBOUND_TYPE = gql`\n  query GetInboundType {\n    create_inboundv3_inbound { ...InbTypeFields }\n  }\n  ${INB_FRAG}\n`;\n",
+		"gqls/fragments.ts":        "export const INB_FRAG = gql`\n  fragment InbTypeFields on InboundV3Inbound { inb_type { id name } }\n`;\n",
+		"hooks/useInboundType.ts":  "import { GET_INBOUND_TYPE as Q } from '@wms/gqls/inbound';\nexport const useInboundType = () => useQuery(Q);\n",
+		"pages/Inbound/index.tsx":  "import { useInboundType } from '@wms/hooks/queries/useInboundType';\n",
+		"pages/Unrelated/index.ts": "import { useOther } from '@wms/hooks/queries/useOther';\n",
+		"components/Inbound.tsx":   "import { useInboundType } from '@wms/hooks/queries/useInboundType';\n",
+		"gqls/subscription.ts":     "export const ON_INBOUND = gql`\n  subscription OnInbound { create_inboundv3_inbound { inb_type { name } } }\n`;\n",
+	})
+
+	schema := main.LoadSchema("mini.graphql")
+	usages := main.WhoUses(schema, dir, "InboundV3Type", "name")
+	if len(usages) != 1 {
+		t.Fatal("expected usage through fragment, got: ", usages)
+	}
+	u := usages[0]
+	t.Log(u.Operation, u.Position, u.Hooks, u.Pages)
+	if u.Operation != "query GetInboundType" || len(u.Hooks) != 1 || len(u.Pages) != 1 || !strings.HasSuffix(u.Pages[0], "pages/Inbound/index.tsx") {
+		t.Error("unexpected usage: ", u)
+	}
+
+	if usages := main.WhoUses(schema, dir, "InboundV3Inbound", "status"); len(usages) != 0 {
+		t.Error("unexpected usages: ", usages)
+	}
+	t.Log("---done---")
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	docs := []main.GQLDocument{
+		{SourcePath: "fragments.ts", Body: `fragment InbTypeFields on InboundV3Inbound { inb_type { id name } }`},
+		{SourcePath: "useInbound.ts", Body: `query GetInbound($in: InboundV3Input!) { inbound: create_inboundv3_inbound(in: $in) { id ...InbTypeFields } }`},
+		{SourcePath: "useInboundQuery.ts", Body: `query GetStatusQuery { create_inboundv3_inbound { __typename status } }`},
+	}
+
+	ts := main.GenerateTypeScript(schema, docs)
+	t.Log(ts)
+	for _, expected := range []string{
+		"export type GetInboundQuery = {\n  inbound: {\n    id: number | null;\n    inb_type: {\n      id: number;\n      name: string;\n    } | null;\n  } | null;\n};",
+		"export type GetInboundQueryVariables = {\n  in: InboundV3Input;\n};",
+		"  status?: 'DRAFT' | 'CLOSED' | 'DONE' | null;",
+		"export type InboundV3InboundParameterInput = {",
+		"export type GetStatusQuery = {\n  create_inboundv3_inbound: {\n    __typename: string;\n",
+		"export type GetStatusQueryVariables = {};",
+	} {
+		if !strings.Contains(ts, expected) {
+			t.Error("missing: " + expected)
+		}
+	}
+	t.Log("---done---")
+}
+
+func TestRewriteSource(t *testing.T) {
+	t.Log("---start---")
+	content := "export const GET_STOCK = gql`\n  query GetStock($where: stock_inventory_bool_exp) {\n    stock_inventory(where: $where) { id }\n    inv: stock_inventory { id }\n  }\n  ${FRAG}\n`;\n"
+	renames := main.Renames{
+		Fields: map[string]string{"stock_inventory": "stockInventory"},
+		Args:   map[string]string{"stock_inventory.where": "filter"},
+		Types:  map[string]string{"stock_inventory_bool_exp": "StockInventoryFilter"},
+	}
+
+	rewritten, count := main.RewriteSource("useStock.ts", content, renames)
+	t.Log(rewritten)
+	expected := "export const GET_STOCK = gql`\n  query GetStock($where: StockInventoryFilter) {\n    stock_inventory: stockInventory(filter: $where) { id }\n    inv: stockInventory { id }\n  }\n  ${FRAG}\n`;\n"
+	if count != 4 || rewritten != expected {
+		t.Error("unexpected rewrite: ", count, rewritten)
+	}
+	t.Log("---done---")
+}
+
+func TestFormatSource(t *testing.T) {
+	t.Log("---start---")
+	content := "export const GET_STOCK = gql`\n  query GetStock { stock_inventory { lot_number id ...StockFields } }\n  ${FRAG}\n`;\n"
+
+	formatted := main.FormatSource("useStock.ts", content, true)
+	t.Log(formatted)
+	expected := "export const GET_STOCK = gql`\n  query GetStock {\n    stock_inventory {\n      id\n      lot_number\n      ... StockFields\n    }\n  }\n\n  ${FRAG}\n`;\n"
+	if formatted != expected {
+		t.Error("unexpected format: " + formatted)
+	}
+	if again := main.FormatSource("useStock.ts", formatted, true); again != formatted {
+		t.Error("format is not stable: " + again)
+	}
+	t.Log("---done---")
+}
+
+func TestLint(t *testing.T) {
+	t.Log("---start---")
+	schemaFile := filepath.Join(t.TempDir(), "schema.graphql")
+	schemaSDL := "schema { query: query_root }\ntype stock { id: Int! lot_number: String items: [stock_item!]! }\ntype stock_item { id: Int! qty: Int }\ntype query_root { stock(limit: Int, where: String): [stock!]! }\n"
+	if err := os.WriteFile(schemaFile, []byte(schemaSDL), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schema := main.LoadSchema(schemaFile)
+	docs := []main.GQLDocument{
+		{SourcePath: "useA.ts", Line: 1, Column: 1, Body: "query GetStock($where: String, $unused: Int, $withLot: Boolean!) {\n  stock(where: $where) { lot_number @include(if: $withLot) items { qty } }\n}"},
+		{SourcePath: "useB.ts", Line: 1, Column: 1, Body: "query GetStock { stock(limit: 1) { id ...Unused } }\nfragment Unused on stock { id }\nfragment Orphan on stock { id }"},
+		{SourcePath: "useC.ts", Line: 1, Column: 1, Body: "{ stock(limit: 1) { id items { id } } }"},
+	}
+
+	rules := map[string]int{}
+	for _, issue := range main.Lint(schema, docs, map[string]bool{"max-depth": true}, 6) {
+		t.Log(issue.Position, issue.Rule, issue.Message)
+		rules[issue.Rule]++
+	}
+	expected := map[string]int{
+		"unused-variable":          1,
+		"missing-limit":            1,
+		"list-without-id":          2,
+		"duplicate-operation-name": 1,
+		"unused-fragment":          1,
+		"anonymous-operation":      1,
+	}
+	for rule, count := range expected {
+		if rules[rule] != count {
+			t.Errorf("expected %d %s issues, got %d", count, rule, rules[rule])
+		}
+	}
+
+	if issues := main.Lint(schema, docs[2:], nil, 1); len(issues) != 2 || issues[1].Rule != "max-depth" {
+		t.Error("expected anonymous operation and max depth, got: ", issues)
+	}
+
+	sorted := []main.GQLDocument{
+		{SourcePath: "useD.ts", Line: 10, Column: 1, Body: "{ stock(limit: 1) { id } }"},
+		{SourcePath: "useD.ts", Line: 9, Column: 1, Body: "{ stock(limit: 1) { id } }"},
+	}
+	if issues := main.Lint(schema, sorted, nil, 6); len(issues) != 2 || issues[0].Position != "useD.ts:9:1" || issues[1].Position != "useD.ts:10:1" {
+		t.Error("expected issues sorted by line, got: ", issues)
+	}
+	t.Log("---done---")
+}
+
+func TestFindOperationCollisions(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	docs := []main.GQLDocument{
+		{SourcePath: "fragments.ts", Body: `fragment InbTypeFields on InboundV3Inbound { inb_type { id name } }`},
+		{SourcePath: "useA.ts", Body: `query GetInbound { create_inboundv3_inbound { id ...InbTypeFields } }`},
+		{SourcePath: "useB.ts", Body: `query GetInbound { create_inboundv3_inbound { id status inb_type { id } } }`},
+		{SourcePath: "useC.ts", Body: `query GetOther { create_inboundv3_inbound { id } }`},
+	}
+
+	collisions := main.FindOperationCollisions(schema, docs)
+	if len(collisions) != 1 || len(collisions[0].Positions) != 2 {
+		t.Fatal("expected GetInbound collision, got: ", collisions)
+	}
+	diff := collisions[0].Diffs[0]
+	t.Log(diff.Position, diff.Removed, diff.Added)
+	if len(diff.Removed) != 1 || diff.Removed[0] != "create_inboundv3_inbound.inb_type.name" ||
+		len(diff.Added) != 1 || diff.Added[0] != "create_inboundv3_inbound.status" {
+		t.Error("unexpected diff: ", diff)
+	}
+
+	// positions count runes, the appended GetFragment is not useD.ts' own
+	docs = []main.GQLDocument{
+		{SourcePath: "useD.ts", Body: "# états à réviser, ébauche: à compléter après révision\nquery GetInbound { create_inboundv3_inbound { ...InbTypeFields } }"},
+		{SourcePath: "fragments.ts", Body: "query GetFragment { create_inboundv3_inbound { id } }\nfragment InbTypeFields on InboundV3Inbound { id }"},
+	}
+	if collisions := main.FindOperationCollisions(schema, docs); len(collisions) != 0 {
+		t.Error("appended operation counted twice: ", collisions)
+	}
+	t.Log("---done---")
+}
+
+func TestParseIgnoreList(t *testing.T) {
+	t.Log("---start---")
+	list, err := main.ParseIgnoreList("# heavy types\r\nIgnored*\r\n\n*_aggregate_fields\n/^audit_.+_log$/\n!IgnoredKeep\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Len() != 4 {
+		t.Error("expected 4 patterns, got: ", list.Len())
+	}
+	for name, expected := range map[string]bool{
+		"IgnoredInboundV3Type":             true,
+		"IgnoredKeep":                      false,
+		"stock_inventory_aggregate_fields": true,
+		"audit_stock_log":                  true,
+		"stock_inventory":                  false,
+		"":                                 false,
+	} {
+		if list.IgnoresType(name) != expected {
+			t.Errorf("IgnoresType(%q) should be %v", name, expected)
+		}
+	}
+
+	fields, err := main.ParseIgnoreList("stock_inventory.audit_logs\n*.*_aggregate\n!stock_inventory.items_aggregate\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields.IgnoresType("stock_inventory") {
+		t.Error("field patterns must not ignore types")
+	}
+	for field, expected := range map[string]bool{
+		"stock_inventory.audit_logs":      true,
+		"stock_item.audit_logs":           false,
+		"stock_item.items_aggregate":      true,
+		"stock_inventory.items_aggregate": false,
+		"stock_inventory.lot_number":      false,
+	} {
+		typeName, fieldName, _ := strings.Cut(field, ".")
+		if fields.IgnoresField(typeName, fieldName) != expected {
+			t.Errorf("IgnoresField(%s) should be %v", field, expected)
+		}
+	}
+
+	if _, err := main.ParseIgnoreList("/[/"); err == nil {
+		t.Error("expected invalid regex error")
+	}
+	t.Log("---done---")
+}
+
+func TestFindTypePaths(t *testing.T) {
+	t.Log("---start---")
+	schemaFile := filepath.Join(t.TempDir(), "schema.graphql")
+	schemaSDL := "type Query { outbound: outbound }\n" +
+		"type outbound { id: Int! from_location: warehouse_location to_location: warehouse_location work: outbound_work }\n" +
+		"type outbound_work { zone: zone }\n" +
+		"type zone { location: warehouse_location }\n" +
+		"type warehouse_location { id: Int! outbound: outbound }\n"
+	if err := os.WriteFile(schemaFile, []byte(schemaSDL), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schema := main.LoadSchema(schemaFile)
+
+	paths := main.FindTypePaths(schema, "outbound", "warehouse_location", 0)
+	for _, p := range paths {
+		t.Log(p.Path, p.Types)
+	}
+	if len(paths) != 2 || paths[0].Path != "outbound.from_location" || paths[1].Path != "outbound.to_location" {
+		t.Error("expected both shortest paths, got: ", paths)
+	}
+
+	paths = main.FindTypePaths(schema, "outbound", "warehouse_location", 3)
+	if len(paths) != 3 || paths[2].Path != "outbound.work.zone.location" || len(paths[2].Types) != 4 {
+		t.Error("expected every path up to 3 fields, got: ", paths)
+	}
+
+	if paths := main.FindTypePaths(schema, "warehouse_location", "Query", 0); len(paths) != 0 {
+		t.Error("unexpected paths: ", paths)
+	}
+	t.Log("---done---")
+}
+
+func TestRenderGraph(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	defs := []*ast.Definition{schema.Types["InboundV3Inbound"], schema.Types["InboundV3Status"], schema.Types["InboundV3Input"]}
+
+	dot := main.RenderGraph(defs, "dot")
+	t.Log(dot)
+	for _, expected := range []string{
+		`"InboundV3Status" [shape=ellipse];`,
+		`"InboundV3Input" [shape=note];`,
+		`"InboundV3Inbound" -> "InboundV3Status" [label="status"];`,
+		`"InboundV3Input" -> "InboundV3Status" [label="status"];`,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Error("missing: " + expected)
+		}
+	}
+	if strings.Contains(dot, "InboundV3Type") {
+		t.Error("unexpected edge to a type outside the graph")
+	}
+
+	mermaid := main.RenderGraph(defs, "mermaid")
+	t.Log(mermaid)
+	if !strings.HasPrefix(mermaid, "graph LR\n") || !strings.Contains(mermaid, "  InboundV3Inbound -->|status| InboundV3Status\n") {
+		t.Error("unexpected mermaid graph: " + mermaid)
+	}
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"useA.ts":   "export const A = gql`\n  query A { create_inboundv3_inbound { inb_type { name } } }\n`;\n",
+		"useB.ts":   "export const B = gql`\n  query B { create_inboundv3_inbound { status } }\n`;\n",
+		"useSub.ts": "export const S = gql`\n  subscription S { create_inboundv3_inbound { id } }\n`;\n",
+	})
+	merged := main.RenderGraph(main.GraphDefinitions(schema, dir, "", 0), "dot")
+	t.Log(merged)
+	for _, expected := range []string{
+		`"InboundV3Inbound" -> "InboundV3Type" [label="inb_type"];`,
+		`"InboundV3Inbound" -> "InboundV3Status" [label="status"];`,
+	} {
+		if !strings.Contains(merged, expected) {
+			t.Error("missing edge across documents: " + expected)
+		}
+	}
+	t.Log("---done---")
+}
+
+func TestExplore(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	in := strings.NewReader("find inbound\n3\nmark 1\n4\nmark 2\nback\nemit query\nemit type\nquit\n")
+	var out strings.Builder
+
+	main.Explore(schema, in, &out)
+	t.Log(out.String())
+	for _, expected := range []string{
+		"  7. enum InboundV3Status\n",
+		" *1. id: Int\n",
+		"InboundV3Inbound.inb_type > ",
+		"fragment InboundV3InboundFields on InboundV3Inbound {\n  id\n  inb_type {\n    name\n  }\n}\n",
+		"type InboundV3Type {\n  name: String!\n}\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Error("missing: " + expected)
+		}
+	}
+
+	out.Reset()
+	main.Explore(schema, strings.NewReader("type InboundV3Input\nmark 1 3\nemit type\nemit query\nquit\n"), &out)
+	t.Log(out.String())
+	for _, expected := range []string{
+		"input InboundV3InboundParameterInput {\n  key: String!\n  value: String!\n}\n",
+		"input InboundV3Input {\n  inb_type: String!\n  parameters: [InboundV3InboundParameterInput]\n}\n",
+		"InboundV3Input is an input, only object types can be queried",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Error("missing: " + expected)
+		}
+	}
+	if strings.Contains(out.String(), "type InboundV3Input") || strings.Contains(out.String(), "fragment InboundV3InputFields") {
+		t.Error("input emitted as an output type")
+	}
+	t.Log("---done---")
+}
+
+func TestExploreTerminal(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	const down, left = "\x1b[B", "\x1b[D"
+	keys := "inbound" + down + down + "\r" + " " + down + down + down + "\r" + down + " " + left + "e" + "\x1b" + "/status\r" + "q"
+	var out strings.Builder
+
+	emitted := main.ExploreTerminal(schema, strings.NewReader(keys), &out, 60, 12)
+	screens := strings.Split(out.String(), "\x1b[H\x1b[2J")
+	t.Log(strings.Join(screens, "\n=====\n"))
+	if len(screens) != len([]rune(strings.ReplaceAll(strings.ReplaceAll(keys, down, "v"), left, "<")))+1 {
+		t.Error("expected a screen per key, got: ", len(screens)-1)
+	}
+	for _, expected := range []string{
+		"find: inbound_",
+		"\x1b[7mtype InboundV3Inbound\x1b[0m",
+		"gqlsch explore  InboundV3Inbound.inb_type",
+		"\x1b[7m[x] name: String!\x1b[0m",
+		"[x] id: Int",
+		"inb_type: InboundV3Type",
+		"enum InboundV3Status",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Error("missing: " + expected)
+		}
+	}
+	if len(emitted) != 1 || emitted[0] != "fragment InboundV3InboundFields on InboundV3Inbound {\n  id\n  inb_type {\n    name\n  }\n}" {
+		t.Error("unexpected emitted documents: ", emitted)
+	}
+	for _, screen := range screens[1:] {
+		if lines := strings.Split(screen, "\r\n"); len(lines) != 12 {
+			t.Errorf("expected 12 lines, got %d", len(lines))
+		}
+	}
+	t.Log("---done---")
+}
+
+func TestSearch(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+
+	results := main.Search(schema, "inbound_tipe")
+	for _, r := range results {
+		t.Log(r.Kind, r.Name, r.Type, r.Distance)
+	}
+	if len(results) != 1 || results[0].Kind != "type" || results[0].Name != "InboundV3Type" {
+		t.Error("expected the misspelled type, got: ", results)
+	}
+
+	results = main.Search(schema, "create_inbound")
+	if len(results) != 1 || results[0].Kind != "query" || results[0].Distance != 0 {
+		t.Error("expected the root field, got: ", results)
+	}
+	t.Log("---done---")
+}
+
 func getPrefixPath() string {
 	envPrefixPath := os.Getenv("PREFIX_PATH")
 	if envPrefixPath == "" {
@@ -79,3 +583,483 @@ func getPrefixPath() string {
 
 	return envPrefixPath
 }
+
+func TestWriteModels(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	path := filepath.Join(t.TempDir(), "models", "models.go")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	defs := []*ast.Definition{schema.Types["InboundV3Inbound"], schema.Types["InboundV3Type"]}
+
+	main.WriteModels(path, schema, defs)
+	main.WriteModels(path, schema, defs)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	models := string(content)
+	t.Log(models)
+	if _, err := parser.ParseFile(token.NewFileSet(), path, content, 0); err != nil {
+		t.Error("invalid Go source: ", err)
+	}
+	for _, expected := range []string{
+		"package models\n",
+		"ID         *int             `json:\"id\"`",
+		"Status     *InboundV3Status `json:\"status\"`",
+		"InbType    *InboundV3Type   `json:\"inb_type\"`",
+		// InboundV3InboundParameter is not emitted
+		"Parameters []interface{}    `json:\"parameters\"`",
+		"ID        int           `json:\"id\"`",
+		"CreatedAt *graphql.Time `json:\"created_at\"`",
+		"type InboundV3Status string",
+	} {
+		if !strings.Contains(models, expected) {
+			t.Error("missing: " + expected)
+		}
+	}
+	if strings.Count(models, "type InboundV3Inbound struct") != 1 {
+		t.Error("models declared twice")
+	}
+	t.Log("---done---")
+}
+
+func TestWriteResolvers(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	queryDoc, errs := main.ValidateQuery(schema, `query GetInbound($in: InboundV3Input) { inbound: create_inboundv3_inbound(in: $in) { id status inb_type { name } } }`)
+	if queryDoc == nil {
+		t.Fatal(errs)
+	}
+	stubs := main.QueryResolverStubs(schema, queryDoc)
+	if len(stubs) != 1 {
+		t.Fatal("expected a stub per root field, got: ", stubs)
+	}
+
+	hasura := main.HasuraDocument(stubs[0])
+	t.Log(hasura)
+	if doc, errs := main.ValidateQuery(schema, hasura); doc == nil || len(errs) > 0 {
+		t.Error("invalid Hasura document: ", errs)
+	}
+
+	path := filepath.Join(t.TempDir(), "graph", "hasura.resolvers.go")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	main.WriteResolvers(path, schema, stubs)
+	main.WriteResolvers(path, schema, stubs)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolvers := string(content)
+	t.Log(resolvers)
+	if _, err := parser.ParseFile(token.NewFileSet(), path, content, 0); err != nil {
+		t.Error("invalid Go source: ", err)
+	}
+	if formatted, err := format.Source(content); err != nil || string(formatted) != resolvers {
+		t.Error("resolvers are not gofmt formatted: ", err)
+	}
+	for _, expected := range []string{
+		"package graph\n",
+		"\"context\"\n",
+		"func (r *queryResolver) CreateInboundv3Inbound(ctx context.Context, in *InboundV3Input) (*InboundV3Inbound, error) {",
+		"\"in\": in,",
+	} {
+		if !strings.Contains(resolvers, expected) {
+			t.Error("missing: " + expected)
+		}
+	}
+	if strings.Count(resolvers, "CreateInboundv3Inbound(") != 1 {
+		t.Error("resolver stub written twice")
+	}
+
+	// non-null inputs are passed by value, directive variables forwarded
+	schemaFile := filepath.Join(t.TempDir(), "schema.graphql")
+	schemaSDL := "schema { query: query_root mutation: mutation_root }\ntype stock { id: Int! lot_number: String }\ninput stock_insert_input { lot_number: String }\ntype query_root { stock: [stock!]! }\ntype mutation_root { insert_stock_one(object: stock_insert_input!): stock }\n"
+	if err := os.WriteFile(schemaFile, []byte(schemaSDL), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schema = main.LoadSchema(schemaFile)
+	queryDoc, errs = main.ValidateQuery(schema, `mutation InsertStock($object: stock_insert_input!, $withLot: Boolean!) { insert_stock_one(object: $object) { id lot_number @include(if: $withLot) } }`)
+	if queryDoc == nil || len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	stubs = main.QueryResolverStubs(schema, queryDoc)
+	hasura = main.HasuraDocument(stubs[0])
+	t.Log(hasura)
+	if doc, errs := main.ValidateQuery(schema, hasura); doc == nil || len(errs) > 0 {
+		t.Error("invalid Hasura document: ", errs)
+	}
+
+	path = filepath.Join(t.TempDir(), "graph", "stock.resolvers.go")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	main.WriteResolvers(path, schema, stubs)
+	if content, err = os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	resolvers = string(content)
+	t.Log(resolvers)
+	for _, expected := range []string{
+		"func (r *mutationResolver) InsertStockOne(ctx context.Context, object StockInsertInput) (*Stock, error) {",
+		"\"withLot\": graphql.GetOperationContext(ctx).Variables[\"withLot\"],",
+		"\"github.com/99designs/gqlgen/graphql\"\n",
+	} {
+		if !strings.Contains(resolvers, expected) {
+			t.Error("missing: " + expected)
+		}
+	}
+	t.Log("---done---")
+}
+
+func TestDescribeOutputs(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	outputs := []string{
+		"type InboundV3Inbound {\n  id: Int\n  inb_type: InboundV3Type # truncated by --depth 1\n}",
+		"input InboundV3Input {\n  inb_type: String!\n  parameters: [InboundV3InboundParameterInput]\n}",
+		"enum InboundV3Status {\n  DRAFT\n  DONE\n}",
+		"}",
+		"type query_root {\n  create_inboundv3_inbound(in:InboundV3Input): InboundV3Inbound\n}",
+	}
+
+	types := main.DescribeOutputs(schema, outputs, "useInbound.ts")
+	t.Logf("%+v", types)
+	if len(types) != 4 {
+		t.Fatal("expected a type per emitted definition, got: ", types)
+	}
+	inbound, input, enum, root := types[0], types[1], types[2], types[3]
+	if inbound.Kind != "type" || inbound.SourceType != "InboundV3Inbound" || inbound.Source != "useInbound.ts" || len(inbound.Fields) != 2 {
+		t.Error("unexpected type: ", inbound)
+	}
+	if f := inbound.Fields[1]; f.Name != "inb_type" || f.Type != "InboundV3Type" || f.Truncated != "by --depth 1" {
+		t.Error("unexpected truncated field: ", f)
+	}
+	if input.Kind != "input" || input.Fields[0].Type != "String!" || input.Fields[1].Type != "[InboundV3InboundParameterInput]" {
+		t.Error("unexpected input: ", input)
+	}
+	if enum.Kind != "enum" || len(enum.Fields) != 2 || enum.Fields[1].Name != "DONE" {
+		t.Error("unexpected enum: ", enum)
+	}
+	if args := root.Fields[0].Args; len(args) != 1 || args[0].Name != "in" || args[0].Type != "InboundV3Input" {
+		t.Error("unexpected args: ", args)
+	}
+	t.Log("---done---")
+}
+
+func TestWriteOutDir(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"inbound.graphqls": "type InboundV3Inbound {\n  id: Int\n  remark: String\n}\n\ntype HandWritten {\n  id: ID!\n}\n",
+	})
+
+	rules := main.SplitRules([]string{"inboundv3=inbound", "source:pages/Stock=stock"})
+	defs := []*ast.Definition{schema.Types["InboundV3Inbound"], schema.Types["InboundV3Status"], schema.Types["timestamptz"], schema.Types["query_root"]}
+	main.WriteOutDir(dir, rules, defs, "src/pages/Stock/index.ts")
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log(name, "\n", string(content))
+		return string(content)
+	}
+	inbound := read("inbound.graphqls")
+	for _, expected := range []string{"remark: String", "type HandWritten {", "status: InboundV3Status", "enum InboundV3Status {"} {
+		if !strings.Contains(inbound, expected) {
+			t.Error("missing in inbound.graphqls: " + expected)
+		}
+	}
+	if strings.Count(inbound, "id: Int") != 1 {
+		t.Error("merged field duplicated")
+	}
+	if !strings.Contains(read("common.graphqls"), "scalar timestamptz") {
+		t.Error("scalar not in common.graphqls")
+	}
+	if !strings.Contains(read("stock.graphqls"), "type query_root {") {
+		t.Error("source rule not applied")
+	}
+
+	// route rules resolve the page of the source from its index.ts
+	ui := t.TempDir()
+	writeTree(t, ui, map[string]string{
+		"pages/InventoryMoveStock/index.ts":              "export default { path: '/inventory/move-stock', component: Page }\n",
+		"pages/InventoryMoveStock/hooks/useMoveStock.ts": "",
+	})
+	rules = main.SplitRules([]string{"route:/inventory=inventory", "route:/outbound=outbound"})
+	main.WriteOutDir(dir, rules, []*ast.Definition{schema.Types["InboundV3Type"]}, filepath.Join(ui, "pages/InventoryMoveStock/hooks/useMoveStock.ts"))
+	if !strings.Contains(read("inventory.graphqls"), "type InboundV3Type {") {
+		t.Error("route rule not applied")
+	}
+	t.Log("---done---")
+}
+
+func TestWriteGqlgenModels(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"gqlgen.yml": "schema:\n  - graph/*.graphqls\n# hand written bindings\nmodels:\n  InboundV3Inbound:\n    model:\n      - example.com/custom.Inbound\n  timestamptz:\n    model:\n      - time.Time\n",
+	})
+	path := filepath.Join(dir, "gqlgen.yml")
+
+	defs := []*ast.Definition{schema.Types["InboundV3Inbound"], schema.Types["InboundV3Type"]}
+	main.WriteGqlgenModels(path, schema, defs, []string{"timestamptz=example.com/scalar.Time"}, "example.com/graph/model")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gqlgen := string(content)
+	t.Log(gqlgen)
+	for _, expected := range []string{
+		"  - graph/*.graphqls\n",
+		"# hand written bindings\n",
+		"      - example.com/custom.Inbound\n",
+		"      - time.Time\n",
+		"  InboundV3Type:\n    model:\n      - example.com/graph/model.InboundV3Type\n",
+	} {
+		if !strings.Contains(gqlgen, expected) {
+			t.Error("missing: " + expected)
+		}
+	}
+	if strings.Contains(gqlgen, "model.InboundV3Inbound") || strings.Contains(gqlgen, "scalar.Time") {
+		t.Error("hand written binding overwritten")
+	}
+	t.Log("---done---")
+}
+
+func TestPrintSchemaFieldDepth(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+
+	if outputs := main.PrintSchemaField(schema, "InboundV3Inbound", 1); len(outputs) != 1 || !strings.Contains(outputs[0], "status: InboundV3Status # truncated by --depth 1") {
+		t.Error("expected only the printed type at depth 1, got: ", outputs)
+	}
+
+	// sibling branches each get the full depth
+	outputs := main.PrintSchemaField(schema, "InboundV3Inbound", 2)
+	t.Log(strings.Join(outputs, "\n"))
+	printed := strings.Join(outputs, "\n")
+	if len(outputs) != 4 {
+		t.Error("expected the type and its 3 nested types, got: ", len(outputs))
+	}
+	for _, expected := range []string{"enum InboundV3Status {", "type InboundV3Type {", "type InboundV3InboundParameter {", "created_at: timestamptz # truncated by --depth 2"} {
+		if !strings.Contains(printed, expected) {
+			t.Error("missing: " + expected)
+		}
+	}
+
+	warnings := main.WithFlags(2, main.IgnoreList{}, func() {
+		outputs = main.PrintSchemaField(schema, "InboundV3Inbound", 5)
+	})
+	printed = strings.Join(outputs, "\n")
+	t.Log(printed)
+	if len(outputs) != 2 || !strings.Contains(printed, "inb_type: InboundV3Type # truncated by --max-types 2") || !strings.Contains(printed, "parameters: [InboundV3InboundParameter] # truncated by --max-types 2") {
+		t.Error("expected --max-types to cut the traversal, got: ", printed)
+	}
+	if len(warnings) != 0 {
+		t.Error("unexpected warnings: ", warnings)
+	}
+	t.Log("---done---")
+}
+
+func TestPrintSchemaFieldUnion(t *testing.T) {
+	t.Log("---start---")
+	schemaFile := filepath.Join(t.TempDir(), "schema.graphql")
+	schemaSDL := "schema { query: query_root }\ntype A { id: Int }\ntype B { name: String }\nunion AB = A | B\ntype Holder { id: Int ab: AB }\ntype query_root { holder: Holder }\n"
+	if err := os.WriteFile(schemaFile, []byte(schemaSDL), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schema := main.LoadSchema(schemaFile)
+
+	outputs := main.PrintSchemaField(schema, "Holder", 5)
+	printed := strings.Join(outputs, "\n")
+	t.Log(printed)
+	if len(outputs) != 4 || !strings.Contains(printed, "union AB =\n  | A\n  | B") || !strings.Contains(printed, "type B {") {
+		t.Error("expected the union and its members, got: ", printed)
+	}
+
+	outputs = main.PrintSchemaField(schema, "Holder", 2)
+	t.Log(strings.Join(outputs, "\n"))
+	types := main.DescribeOutputs(schema, outputs, "")
+	if len(types) != 2 || types[1].Kind != "union" || len(types[1].Fields) != 2 || types[1].Fields[0].Truncated != "by --depth 2" {
+		t.Error("expected the union members truncated by --depth, got: ", types)
+	}
+	t.Log("---done---")
+}
+
+func TestTrimmedOutputsIgnored(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	ignoreList, err := main.ParseIgnoreList("InboundV3Type\nInboundV3Inbound.status\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	queryDoc, errs := main.ValidateQuery(schema, `query GetInbound { create_inboundv3_inbound { id status inb_type { name } } }`)
+	if queryDoc == nil {
+		t.Fatal(errs)
+	}
+
+	var outputs []string
+	warnings := main.WithFlags(0, ignoreList, func() {
+		outputs = main.TrimmedOutputs(schema, queryDoc)
+	})
+	printed := strings.Join(outputs, "\n")
+	t.Log(printed, warnings)
+	if strings.Contains(printed, "InboundV3Type {") || strings.Contains(printed, "status:") {
+		t.Error("ignored type or field emitted: ", printed)
+	}
+	expected := []string{
+		"query GetInbound: create_inboundv3_inbound.status selects ignored field InboundV3Inbound.status, the output is incomplete",
+		"query GetInbound: create_inboundv3_inbound.inb_type selects ignored type InboundV3Type, the output is incomplete",
+	}
+	for _, warning := range expected {
+		if !containsWarning(warnings, warning) {
+			t.Error("missing warning: " + warning)
+		}
+	}
+	t.Log("---done---")
+}
+
+func TestSourceOperations(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	// the first literal of useInbound.ts only defines a fragment
+	docs := []main.GQLDocument{
+		{SourcePath: "useInbound.ts", Body: `fragment InbTypeFields on InboundV3Inbound { inb_type { id name } }`},
+		{SourcePath: "useInbound.ts", Body: `query GetInbound { create_inboundv3_inbound { id ...InbTypeFields } }`},
+		{SourcePath: "useStatus.ts", Body: `query GetStatus { create_inboundv3_inbound { status ...InbTypeFields } }`},
+	}
+
+	var (
+		sources    []string
+		operations map[string]*ast.QueryDocument
+		invalid    int
+	)
+	warnings := main.WithFlags(0, main.IgnoreList{}, func() {
+		sources, operations, invalid = main.SourceOperations(schema, docs)
+	})
+	if invalid != 0 {
+		t.Error("shared fragment reported as invalid: ", warnings)
+	}
+	if len(sources) != 2 || sources[0] != "useInbound.ts" || sources[1] != "useStatus.ts" {
+		t.Fatal("unexpected sources: ", sources)
+	}
+	for source, name := range map[string]string{"useInbound.ts": "GetInbound", "useStatus.ts": "GetStatus"} {
+		if ops := operations[source].Operations; len(ops) != 1 || ops[0].Name != name {
+			t.Errorf("%s: expected only %s, got %v", source, name, ops)
+		}
+	}
+	printed := strings.Join(main.TrimmedOutputs(schema, operations["useInbound.ts"]), "\n")
+	t.Log(printed)
+	if !strings.Contains(printed, "type InboundV3Type {") {
+		t.Error("fragment types not emitted: ", printed)
+	}
+
+	docs = append(docs, main.GQLDocument{SourcePath: "unused.ts", Body: `fragment Unused on InboundV3Inbound { id }`})
+	warnings = main.WithFlags(0, main.IgnoreList{}, func() {
+		_, _, invalid = main.SourceOperations(schema, docs)
+	})
+	if invalid != 1 || len(warnings) != 1 || !strings.HasSuffix(warnings[0], `Fragment "Unused" is never used.`) {
+		t.Error("expected the unused fragment reported, got: ", warnings)
+	}
+	t.Log("---done---")
+}
+
+func TestFieldSelection(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	def := schema.Types["InboundV3Inbound"]
+
+	for _, fields := range []string{"id,status,inb_type{name}", "{ id status inb_type { name } }"} {
+		selectionSet, err := main.ParseFieldSelection(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := main.CheckSelection(schema, def, selectionSet); err != nil {
+			t.Fatal(err)
+		}
+		printed := strings.Join(main.ProcessSelectionSet(schema, def, selectionSet), "\n")
+		t.Log(printed)
+		for _, expected := range []string{"type InboundV3Inbound {\n  id: Int\n  status: InboundV3Status\n  inb_type: InboundV3Type\n}", "type InboundV3Type {\n  name: String!\n}", "enum InboundV3Status {"} {
+			if !strings.Contains(printed, expected) {
+				t.Errorf("%s: missing %s", fields, expected)
+			}
+		}
+	}
+
+	for fields, expected := range map[string]string{
+		"id,stauts":       "unknown field stauts on type InboundV3Inbound (did you mean status?)",
+		"id{name}":        "InboundV3Inbound.id is a leaf",
+		"inb_type":        "InboundV3Inbound.inb_type needs a selection",
+		"inb_type{nme}":   "unknown field nme on type InboundV3Type",
+		"...InbTypeField": "only accepts fields",
+	} {
+		selectionSet, err := main.ParseFieldSelection(fields)
+		if err == nil {
+			err = main.CheckSelection(schema, def, selectionSet)
+		}
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected %q, got %v", fields, expected, err)
+		}
+	}
+	if _, err := main.ParseFieldSelection("id,{"); err == nil {
+		t.Error("expected a parse error")
+	}
+	t.Log("---done---")
+}
+
+func TestSkeletonDocument(t *testing.T) {
+	t.Log("---start---")
+	schema := main.LoadSchema("mini.graphql")
+	fieldDef := schema.Query.Fields.ForName("create_inboundv3_inbound")
+
+	skeleton := main.SkeletonDocument(schema, ast.Query, fieldDef, 2)
+	t.Log(skeleton)
+	if doc, errs := main.ValidateQuery(schema, skeleton); doc == nil || len(errs) > 0 {
+		t.Error("invalid skeleton: ", errs)
+	}
+	for _, expected := range []string{
+		"query CreateInboundv3Inbound ($in: InboundV3Input) {",
+		"create_inboundv3_inbound(in: $in) {",
+		"    inb_type {\n      id\n      name\n      created_at\n    }\n",
+	} {
+		if !strings.Contains(skeleton, expected) {
+			t.Error("missing: " + expected)
+		}
+	}
+	// deprecated fields are left out
+	if strings.Contains(skeleton, "code") {
+		t.Error("deprecated field selected")
+	}
+	t.Log("---done---")
+}
+
+func containsWarning(warnings []string, warning string) bool {
+	for _, w := range warnings {
+		if w == warning {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTree writes files, keyed by their path relative to dir.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// WriteModels appends a Go struct per emitted object and input, and a string
+// type per emitted or referenced enum, to the Go file at path. Types already
+// declared in the file are left untouched. Fields of objects or inputs that
+// are neither emitted nor declared, e.g. cut by --depth, are interface{}.
+func WriteModels(path string, schema *ast.Schema, defs []*ast.Definition) {
+	existing := ""
+	if content, err := os.ReadFile(path); err == nil {
+		existing = string(content)
+	} else if !os.IsNotExist(err) {
+		fatalf("cannot read %s: %v", path, err)
+	}
+
+	// models live in the model package itself
+	mapper := newGoTypeMapper(schema)
+	mapper.modelPkg = ""
+	mapper.declared = map[string]bool{}
+	for _, def := range schema.Types {
+		if strings.Contains(existing, "type "+goName(def.Name)+" ") {
+			mapper.declared[def.Name] = true
+		}
+	}
+
+	for _, def := range defs {
+		switch def.Kind {
+		case ast.Object, ast.InputObject, ast.Enum:
+			mapper.declared[def.Name] = true
+		}
+	}
+
+	// enums are emitted whole, so the ones cut by --depth are added back
+	var enums []*ast.Definition
+	for _, def := range defs {
+		for _, f := range def.Fields {
+			fieldDef := schema.Types[f.Type.Name()]
+			if fieldDef != nil && fieldDef.Kind == ast.Enum && !mapper.declared[fieldDef.Name] {
+				mapper.declared[fieldDef.Name] = true
+				enums = append(enums, fieldDef)
+			}
+		}
+	}
+	defs = append(defs, enums...)
+
+	var body strings.Builder
+	added := 0
+	for _, def := range defs {
+		name := goName(def.Name)
+		if strings.Contains(existing, "type "+name+" ") || strings.Contains(body.String(), "type "+name+" ") {
+			continue
+		}
+
+		switch def.Kind {
+		case ast.Object, ast.InputObject:
+			fmt.Fprintf(&body, "\n// %s is the trimmed %s.\n", name, def.Name)
+			fmt.Fprintf(&body, "type %s struct {\n", name)
+			for _, f := range def.Fields {
+				fmt.Fprintf(&body, "%s %s `json:%q`\n", goName(f.Name), mapper.goType(f.Type), f.Name)
+			}
+			body.WriteString("}\n")
+		case ast.Enum:
+			fmt.Fprintf(&body, "\n// %s is the enum %s.\n", name, def.Name)
+			fmt.Fprintf(&body, "type %s string\n\nconst (\n", name)
+			for _, v := range def.EnumValues {
+				fmt.Fprintf(&body, "%s%s %s = %q\n", name, goName(strings.ToLower(v.Name)), name, v.Name)
+			}
+			body.WriteString(")\n")
+		default:
+			continue
+		}
+		added++
+	}
+
+	writeGoSource(path, existing, "// Models generated by gqlsch for the trimmed schema types. Edit freely.\n", mapper.imports, body.String())
+
+	fmt.Fprintln(stdout, "wrote", added, "models to", path)
+}
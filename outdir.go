@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// splitRule routes emitted types to a .graphqls file of --out-dir, either by
+// type name prefix, by the source file the query came from or by the page
+// route of that file.
+type splitRule struct {
+	Prefix string
+	Source string
+	Route  string
+	File   string
+}
+
+var defaultSplitRules = []string{"stock_=inventory", "outbound_=outbound", "inboundv3_=inbound"}
+
+// commonFile receives scalars and every type no rule matches
+const commonFile = "common.graphqls"
+
+func SplitRules(specs []string) []splitRule {
+	if len(specs) == 0 {
+		specs = defaultSplitRules
+	}
+
+	var rules []splitRule
+	for _, spec := range specs {
+		for _, s := range strings.Split(spec, ",") {
+			match, file, ok := strings.Cut(strings.TrimSpace(s), "=")
+			if !ok || match == "" || file == "" {
+				fatalf("invalid split rule %s, the format must be prefix=file, source:path=file or route:/path=file", s)
+			}
+			if filepath.Ext(file) == "" {
+				file += ".graphqls"
+			}
+
+			if source, isSource := strings.CutPrefix(match, "source:"); isSource {
+				rules = append(rules, splitRule{Source: source, File: file})
+			} else if route, isRoute := strings.CutPrefix(match, "route:"); isRoute {
+				rules = append(rules, splitRule{Route: strings.TrimSuffix(route, "/"), File: file})
+			} else {
+				rules = append(rules, splitRule{Prefix: strings.ToLower(match), File: file})
+			}
+		}
+	}
+
+	return rules
+}
+
+// splitFile returns the file an emitted definition belongs to, type name
+// prefixes taking precedence over source and route rules.
+func splitFile(rules []splitRule, def *ast.Definition, source string) string {
+	if def.Kind == ast.Scalar {
+		return commonFile
+	}
+
+	for _, rule := range rules {
+		if rule.Prefix != "" && strings.HasPrefix(strings.ToLower(def.Name), rule.Prefix) {
+			return rule.File
+		}
+	}
+	if source == "" {
+		return commonFile
+	}
+	for _, rule := range rules {
+		if rule.Source != "" && strings.Contains(source, rule.Source) {
+			return rule.File
+		}
+		if rule.Route != "" {
+			route := pageRoute(filepath.Dir(source))
+			if route == rule.Route || strings.HasPrefix(route, rule.Route+"/") {
+				return rule.File
+			}
+		}
+	}
+
+	return commonFile
+}
+
+// pageRoutes caches the route of each directory looked up by pageRoute.
+var pageRoutes = map[string]string{}
+
+// pageRoute returns the route of the page dir belongs to, read from the path:
+// of the nearest index.ts up to the pages directory as GetEligiblePage does,
+// or "" when dir is not part of a page.
+func pageRoute(dir string) string {
+	if route, exist := pageRoutes[dir]; exist {
+		return route
+	}
+
+	route := ""
+	if filepath.Base(dir) != "pages" {
+		for _, index := range []string{"index.ts", "index.tsx"} {
+			content, err := os.ReadFile(filepath.Join(dir, index))
+			if err != nil {
+				continue
+			}
+			if match := routeRegex.FindStringSubmatch(string(content)); match != nil {
+				route = match[1]
+				break
+			}
+		}
+		if parent := filepath.Dir(dir); route == "" && parent != dir {
+			route = pageRoute(parent)
+		}
+	}
+
+	pageRoutes[dir] = route
+	return route
+}
+
+// parseEmitted parses the emitted definitions back into schema definitions.
+func parseEmitted(outputs []string) (defs []*ast.Definition) {
+	for _, output := range outputs {
+		// processArgument emits a bare "}" for arguments it does not know
+		if strings.TrimSpace(output) == "" || strings.TrimSpace(output) == "}" {
+			continue
+		}
+
+		emitted, err := parser.ParseSchema(&ast.Source{Input: output})
+		if err != nil {
+			warnf("skipping emitted definition %q: %v", output, err)
+			continue
+		}
+		defs = append(defs, emitted.Definitions...)
+	}
+	return
+}
+
+// WriteOutDir groups the emitted definitions by file and merges each group
+// into the existing file of outDir, if any.
+func WriteOutDir(outDir string, rules []splitRule, defs []*ast.Definition, source string) {
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		fatalf("cannot create out dir %s: %v", outDir, err)
+	}
+
+	groups := map[string][]*ast.Definition{}
+	var files []string
+	for _, def := range defs {
+		file := splitFile(rules, def, source)
+		if _, exist := groups[file]; !exist {
+			files = append(files, file)
+		}
+		groups[file] = append(groups[file], def)
+	}
+
+	for _, file := range files {
+		path := filepath.Join(outDir, file)
+		mergeSchemaFile(path, groups[file])
+		fmt.Fprintln(stdout, "wrote", len(groups[file]), "types to", path)
+	}
+}
+
+// mergeSchemaFile adds defs to the schema file at path. Definitions already in
+// the file only gain the fields and enum values they are missing, so types
+// emitted by different queries accumulate rather than clobber each other.
+func mergeSchemaFile(path string, defs []*ast.Definition) {
+	doc := &ast.SchemaDocument{}
+	if content, err := os.ReadFile(path); err == nil {
+		doc, err = parser.ParseSchema(&ast.Source{Input: string(content), Name: path})
+		if err != nil {
+			fatalf("cannot merge into %s: %v", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		fatalf("cannot read %s: %v", path, err)
+	}
+
+	doc.Definitions = mergeDefinitions(doc.Definitions, defs)
+
+	var buf bytes.Buffer
+	formatter.NewFormatter(&buf, formatter.WithIndent("  "), formatter.WithComments()).FormatSchemaDocument(doc)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		fatalf("cannot write %s: %v", path, err)
+	}
+}
+
+// mergeDefinitions adds defs to into. Definitions already in into get the
+// missing fields and enum values appended, the existing ones kept as they are.
+func mergeDefinitions(into ast.DefinitionList, defs []*ast.Definition) ast.DefinitionList {
+	for _, def := range defs {
+		existing := into.ForName(def.Name)
+		if existing == nil {
+			into = append(into, def)
+			continue
+		}
+		for _, f := range def.Fields {
+			if existing.Fields.ForName(f.Name) == nil {
+				existing.Fields = append(existing.Fields, f)
+			}
+		}
+		for _, v := range def.EnumValues {
+			if existing.EnumValues.ForName(v.Name) == nil {
+				existing.EnumValues = append(existing.EnumValues, v)
+			}
+		}
+	}
+	return into
+}
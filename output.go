@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var (
+	// stdout receives the human readable output, discarded with --output json
+	stdout io.Writer = os.Stdout
+	// banner receives the options and ignored file summary, moved to stderr
+	// by commands whose stdout is a generated file
+	banner io.Writer = os.Stdout
+	// report collects the --output json document, nil in text mode
+	report *jsonReport
+)
+
+type jsonReport struct {
+	Options    interface{}     `json:"options"`
+	Operations []jsonOperation `json:"operations"`
+	Types      []jsonType      `json:"types"`
+	Results    interface{}     `json:"results,omitempty"`
+	Warnings   []string        `json:"warnings"`
+	Errors     []string        `json:"errors"`
+}
+
+type jsonOperation struct {
+	Name      string `json:"name"`
+	Operation string `json:"operation"`
+	Position  string `json:"position"`
+}
+
+type jsonType struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// SourceType is the raw schema type the definition was trimmed from
+	SourceType string `json:"source_type,omitempty"`
+	// Source is the file of the operations the type was emitted for, if any
+	Source string      `json:"source,omitempty"`
+	Fields []jsonField `json:"fields,omitempty"`
+}
+
+type jsonField struct {
+	Name        string    `json:"name"`
+	Type        string    `json:"type,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Args        []jsonArg `json:"args,omitempty"`
+	// Truncated tells why the traversal stopped at this field, if it did
+	Truncated string `json:"truncated,omitempty"`
+}
+
+type jsonArg struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+func startReport(format string) {
+	switch format {
+	case "", "text":
+	case "json":
+		stdout = io.Discard
+		banner = io.Discard
+		report = &jsonReport{Options: opts, Operations: []jsonOperation{}, Types: []jsonType{}, Warnings: []string{}, Errors: []string{}}
+	default:
+		fatalf("output format %s is not supported, use text or json", format)
+	}
+}
+
+// flushReport writes the json document, if any, to the real stdout.
+func flushReport() {
+	if report == nil {
+		return
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Error: %v\n", err)
+	}
+}
+
+func warnf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if report != nil {
+		report.Warnings = append(report.Warnings, msg)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "⚠️ Warning: "+msg)
+}
+
+// fatalf reports the error and exits with a non-zero status.
+func fatalf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if report != nil {
+		report.Errors = append(report.Errors, msg)
+		flushReport()
+	} else {
+		fmt.Fprintln(os.Stderr, "⚠️ Error: "+msg)
+	}
+	os.Exit(1)
+}
+
+func recordOperations(doc GQLDocument, queryDoc *ast.QueryDocument) {
+	if report == nil {
+		return
+	}
+
+	for _, op := range queryDoc.Operations {
+		if !doc.owns(op.Position) {
+			continue
+		}
+		report.Operations = append(report.Operations, jsonOperation{
+			Name:      op.Name,
+			Operation: string(op.Operation),
+			Position:  doc.Position(op.Position.Line, op.Position.Column),
+		})
+	}
+}
+
+func recordResults(results interface{}) {
+	if report != nil {
+		report.Results = results
+	}
+}
+
+// emitTypes prints the emitted type definitions, or writes them to --out-dir,
+// and records them for json. source is the file the query came from, if any.
+func emitTypes(schema *ast.Schema, outputs []string, source string) {
+	if opts.OutDir != "" || opts.GqlgenFile != "" || opts.ModelsFile != "" || report != nil {
+		defs := parseEmitted(outputs)
+		if report != nil {
+			report.Types = append(report.Types, describeDefinitions(schema, defs, truncatedFields(outputs), source)...)
+		}
+		if opts.OutDir != "" {
+			WriteOutDir(opts.OutDir, SplitRules(opts.Split), defs, source)
+		}
+		if opts.GqlgenFile != "" {
+			WriteGqlgenModels(opts.GqlgenFile, schema, defs, opts.ScalarMap, opts.ModelPkg)
+		}
+		if opts.ModelsFile != "" {
+			WriteModels(opts.ModelsFile, schema, defs)
+		}
+	}
+
+	if opts.OutDir == "" {
+		for _, output := range outputs {
+			fmt.Fprintln(stdout, output)
+		}
+	}
+}
+
+// DescribeOutputs turns the emitted definitions back into their structured
+// form. The emitted text is parsed rather than the schema read, since fields
+// may be trimmed or synthesized (bool_exp, order_by).
+func DescribeOutputs(schema *ast.Schema, outputs []string, source string) []jsonType {
+	return describeDefinitions(schema, parseEmitted(outputs), truncatedFields(outputs), source)
+}
+
+// sdlKeywords are the kinds as the emitted definitions declare them.
+var sdlKeywords = map[ast.DefinitionKind]string{
+	ast.Scalar:      "scalar",
+	ast.Object:      "type",
+	ast.Interface:   "interface",
+	ast.Union:       "union",
+	ast.Enum:        "enum",
+	ast.InputObject: "input",
+}
+
+// describeDefinitions describes the parsed emitted defs, truncated giving why
+// a Type.field was cut off.
+func describeDefinitions(schema *ast.Schema, defs []*ast.Definition, truncated map[string]string, source string) []jsonType {
+	types := []jsonType{}
+	for _, def := range defs {
+		t := jsonType{Name: def.Name, Kind: sdlKeywords[def.Kind], Source: source}
+		if raw := schema.Types[def.Name]; raw != nil {
+			t.SourceType = raw.Name
+		}
+
+		for _, v := range def.EnumValues {
+			t.Fields = append(t.Fields, jsonField{Name: v.Name})
+		}
+		for _, member := range def.Types {
+			t.Fields = append(t.Fields, jsonField{Name: member, Truncated: truncated[def.Name+"."+member]})
+		}
+		for _, f := range def.Fields {
+			field := jsonField{Name: f.Name, Type: f.Type.String(), Description: f.Description, Truncated: truncated[def.Name+"."+f.Name]}
+			for _, arg := range f.Arguments {
+				field.Args = append(field.Args, jsonArg{Name: arg.Name, Type: arg.Type.String()})
+			}
+			t.Fields = append(t.Fields, field)
+		}
+		types = append(types, t)
+	}
+	return types
+}
+
+// truncatedFields returns why printSchemaField cut off a field, keyed by
+// Type.field, from its trailing "# truncated by ..." comment which the
+// parsed definitions do not keep.
+func truncatedFields(outputs []string) map[string]string {
+	truncated := map[string]string{}
+	for _, output := range outputs {
+		lines := strings.Split(output, "\n")
+		header := strings.Fields(lines[0])
+		if len(header) < 2 {
+			continue
+		}
+		for _, line := range lines[1:] {
+			before, comment, ok := strings.Cut(line, "# truncated")
+			if !ok {
+				continue
+			}
+			name, _, _ := strings.Cut(before, ":")
+			// union members are listed as "| Member"
+			name = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), "|"))
+			truncated[header[1]+"."+name] = strings.TrimSpace(comment)
+		}
+	}
+	return truncated
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// maxTypePaths bounds the paths listed, the schema graph being dense.
+const maxTypePaths = 100
+
+// TypePath is a chain of fields leading from a type to another one.
+type TypePath struct {
+	// Path is the dotted field path starting at the from type
+	Path string `json:"path"`
+	// Types are the types along the path, from first and to last
+	Types []string `json:"types"`
+}
+
+// typeEdge is a field of an object or interface referencing another type.
+type typeEdge struct {
+	Field string
+	Type  string
+}
+
+// typeEdges returns the fields of typeName leading to custom types, ignored
+// types and fields excluded.
+func typeEdges(schema *ast.Schema, typeName string) (edges []typeEdge) {
+	def := schema.Types[typeName]
+	if def == nil || (def.Kind != ast.Object && def.Kind != ast.Interface) {
+		return nil
+	}
+
+	for _, f := range keptFields(def) {
+		nested := f.Type.Name()
+		if !isCustomType(nested) || ignored.IgnoresType(nested) || schema.Types[nested] == nil {
+			continue
+		}
+		edges = append(edges, typeEdge{Field: f.Name, Type: nested})
+	}
+	return
+}
+
+// FindTypePaths returns the shortest field paths from one type to another
+// or, when maxLength is above 0, every path of up to maxLength fields not
+// going through a type twice. At most maxTypePaths paths are returned.
+func FindTypePaths(schema *ast.Schema, from, to string, maxLength int) []TypePath {
+	if maxLength > 0 {
+		var paths []TypePath
+		onPath := map[string]bool{from: true}
+		var walk func(typeName string, fields, types []string)
+		walk = func(typeName string, fields, types []string) {
+			if len(paths) >= maxTypePaths || len(fields) >= maxLength {
+				return
+			}
+			for _, e := range typeEdges(schema, typeName) {
+				if onPath[e.Type] {
+					continue
+				}
+				nextFields := append(append([]string{}, fields...), e.Field)
+				nextTypes := append(append([]string{}, types...), e.Type)
+				if e.Type == to {
+					paths = append(paths, newTypePath(from, nextFields, nextTypes))
+					if len(paths) >= maxTypePaths {
+						return
+					}
+					continue
+				}
+				onPath[e.Type] = true
+				walk(e.Type, nextFields, nextTypes)
+				delete(onPath, e.Type)
+			}
+		}
+		walk(from, nil, []string{from})
+		return paths
+	}
+
+	// breadth first, keeping every parent edge reaching a type at its distance
+	dist := map[string]int{from: 0}
+	parents := map[string][]struct {
+		From string
+		Edge typeEdge
+	}{}
+	queue := []string{from}
+	for len(queue) > 0 {
+		typeName := queue[0]
+		queue = queue[1:]
+		if _, found := dist[to]; found && dist[typeName] >= dist[to] {
+			break
+		}
+		for _, e := range typeEdges(schema, typeName) {
+			d, seen := dist[e.Type]
+			if !seen {
+				dist[e.Type] = dist[typeName] + 1
+				queue = append(queue, e.Type)
+			} else if d != dist[typeName]+1 {
+				continue
+			}
+			parents[e.Type] = append(parents[e.Type], struct {
+				From string
+				Edge typeEdge
+			}{typeName, e})
+		}
+	}
+	if _, found := dist[to]; !found || from == to {
+		return nil
+	}
+
+	var paths []TypePath
+	var back func(typeName string, fields, types []string)
+	back = func(typeName string, fields, types []string) {
+		if len(paths) >= maxTypePaths {
+			return
+		}
+		if typeName == from {
+			reversedFields := make([]string, len(fields))
+			reversedTypes := make([]string, len(types))
+			for i := range fields {
+				reversedFields[i] = fields[len(fields)-1-i]
+			}
+			for i := range types {
+				reversedTypes[i] = types[len(types)-1-i]
+			}
+			paths = append(paths, newTypePath(from, reversedFields, reversedTypes))
+			return
+		}
+		for _, p := range parents[typeName] {
+			back(p.From, append(append([]string{}, fields...), p.Edge.Field), append(append([]string{}, types...), p.From))
+		}
+	}
+	back(to, nil, []string{to})
+	return paths
+}
+
+func newTypePath(from string, fields, types []string) TypePath {
+	return TypePath{Path: from + "." + strings.Join(fields, "."), Types: types}
+}
+
+func reportTypePaths(schemaFilePath, from, to string, maxLength int) {
+	// Load schema
+	schema := LoadSchema(schemaFilePath)
+
+	for _, typeName := range []string{from, to} {
+		if schema.Types[typeName] == nil {
+			fatalf("type %s not found in schema%s", typeName, didYouMean(typeName, typeNames(schema)))
+		}
+	}
+
+	paths := FindTypePaths(schema, from, to, maxLength)
+	recordResults(paths)
+
+	for _, p := range paths {
+		fmt.Fprintf(stdout, "%s: %s\n", p.Path, strings.Join(p.Types, " -> "))
+	}
+	if len(paths) >= maxTypePaths {
+		warnf("only the first %d paths are listed", maxTypePaths)
+	}
+
+	fmt.Fprintf(stdout, "\n-------\n\n")
+	fmt.Fprintln(stdout, "path:", len(paths), "paths from", from, "to", to)
+}
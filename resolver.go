@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// ResolverStub is a root field of the new schema whose resolver forwards the
+// call to Hasura.
+type ResolverStub struct {
+	Operation ast.Operation
+	Field     *ast.FieldDefinition
+	// SelectionSet is forwarded to Hasura, the UI selection in query mode
+	SelectionSet ast.SelectionSet
+	// Variables are the UI variables used inside SelectionSet
+	Variables ast.VariableDefinitionList
+}
+
+var goBuiltinScalars = map[string]string{
+	"Int":     "int",
+	"Float":   "float64",
+	"String":  "string",
+	"Boolean": "bool",
+	"ID":      "string",
+}
+
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true, "goto": true,
+	"if": true, "import": true, "interface": true, "map": true, "package": true, "range": true,
+	"return": true, "select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+// goTypeMapper maps schema types to Go types, collecting the imports needed.
+type goTypeMapper struct {
+	schema   *ast.Schema
+	scalars  map[string]string
+	modelPkg string
+	imports  map[string]bool
+	// declared, when set, limits the object, input and enum types to the ones
+	// declared in the output, the others being interface{}
+	declared map[string]bool
+}
+
+func newGoTypeMapper(schema *ast.Schema) *goTypeMapper {
+	return &goTypeMapper{
+		schema:   schema,
+		scalars:  scalarBindings(opts.ScalarMap),
+		modelPkg: opts.ModelPkg,
+		imports:  map[string]bool{},
+	}
+}
+
+// argType returns the Go type of the argument type t. gqlgen passes non-null
+// inputs by value, only nullable ones get a pointer.
+func (m *goTypeMapper) argType(t *ast.Type) string {
+	if t.Elem != nil || !t.NonNull {
+		return m.goType(t)
+	}
+	return m.namedType(t.NamedType)
+}
+
+// goType returns the Go type of t: slices for lists, pointers for nullable
+// values and for every object or input.
+func (m *goTypeMapper) goType(t *ast.Type) string {
+	if t.Elem != nil {
+		return "[]" + m.goType(t.Elem)
+	}
+
+	name := m.namedType(t.NamedType)
+	if name == "interface{}" {
+		return name
+	}
+	def := m.schema.Types[t.NamedType]
+	if !t.NonNull || (def != nil && (def.Kind == ast.Object || def.Kind == ast.InputObject)) {
+		return "*" + name
+	}
+	return name
+}
+
+func (m *goTypeMapper) namedType(name string) string {
+	if goType, exist := goBuiltinScalars[name]; exist {
+		return goType
+	}
+
+	def := m.schema.Types[name]
+	if def != nil && def.Kind == ast.Scalar {
+		binding, exist := m.scalars[name]
+		if !exist {
+			return "interface{}"
+		}
+		return m.qualify(binding)
+	}
+
+	if m.declared != nil && !m.declared[name] {
+		return "interface{}"
+	}
+	if m.modelPkg == "" {
+		return goName(name)
+	}
+	return m.qualify(m.modelPkg + "." + goName(name))
+}
+
+// qualify turns go/pkg.Type into pkg.Type and records the import.
+func (m *goTypeMapper) qualify(binding string) string {
+	dot := strings.LastIndex(binding, ".")
+	if dot == -1 {
+		return binding
+	}
+	pkgPath := binding[:dot]
+	m.imports[pkgPath] = true
+	return filepath.Base(pkgPath) + binding[dot:]
+}
+
+// goArgName returns the lower camel case Go parameter name of a schema name.
+func goArgName(name string) string {
+	goArg := goName(name)
+	if goArg == "" {
+		return "arg"
+	}
+	if strings.ToUpper(goArg) == goArg {
+		goArg = strings.ToLower(goArg)
+	} else {
+		goArg = strings.ToLower(goArg[:1]) + goArg[1:]
+	}
+	if goKeywords[goArg] {
+		goArg += "Arg"
+	}
+	return goArg
+}
+
+// HasuraDocument builds the operation forwarded to Hasura for stub: every
+// argument of the raw schema field bound to a variable of the same name.
+func HasuraDocument(stub ResolverStub) string {
+	return fieldDocument(stub, stub.Field.Name)
+}
+
+// fieldDocument builds the operation called name selecting the field of stub,
+// every argument bound to a variable of the same name.
+func fieldDocument(stub ResolverStub, name string) string {
+	field := &ast.Field{Name: stub.Field.Name, SelectionSet: inlineFragmentSpreads(stub.SelectionSet)}
+	op := &ast.OperationDefinition{
+		Operation:    stub.Operation,
+		Name:         name,
+		SelectionSet: ast.SelectionSet{field},
+	}
+
+	for _, arg := range stub.Field.Arguments {
+		op.VariableDefinitions = append(op.VariableDefinitions, &ast.VariableDefinition{Variable: arg.Name, Type: arg.Type})
+		field.Arguments = append(field.Arguments, &ast.Argument{Name: arg.Name, Value: &ast.Value{Kind: ast.Variable, Raw: arg.Name}})
+	}
+	for _, vd := range stub.Variables {
+		if op.VariableDefinitions.ForName(vd.Variable) == nil {
+			op.VariableDefinitions = append(op.VariableDefinitions, vd)
+		}
+	}
+
+	var buf bytes.Buffer
+	formatter.NewFormatter(&buf, formatter.WithIndent("  ")).FormatQueryDocument(&ast.QueryDocument{Operations: ast.OperationList{op}})
+	return buf.String()
+}
+
+// inlineFragmentSpreads replaces named fragment spreads by inline fragments,
+// the forwarded document carrying no fragment definitions.
+func inlineFragmentSpreads(selectionSet ast.SelectionSet) ast.SelectionSet {
+	var result ast.SelectionSet
+	for _, sel := range selectionSet {
+		switch s := sel.(type) {
+		case *ast.Field:
+			field := *s
+			field.SelectionSet = inlineFragmentSpreads(s.SelectionSet)
+			result = append(result, &field)
+		case *ast.InlineFragment:
+			inline := *s
+			inline.SelectionSet = inlineFragmentSpreads(s.SelectionSet)
+			result = append(result, &inline)
+		case *ast.FragmentSpread:
+			if s.Definition == nil {
+				continue
+			}
+			result = append(result, &ast.InlineFragment{
+				TypeCondition: s.Definition.TypeCondition,
+				SelectionSet:  inlineFragmentSpreads(s.Definition.SelectionSet),
+			})
+		}
+	}
+	return result
+}
+
+// scalarSelection selects every scalar and enum field of def that is neither
+// deprecated nor ignored, descending into object fields while depth allows.
+func scalarSelection(schema *ast.Schema, def *ast.Definition, depth uint) ast.SelectionSet {
+	var selectionSet ast.SelectionSet
+	if def == nil || depth == 0 {
+		return selectionSet
+	}
+
+	for _, f := range def.Fields {
+		if strings.HasPrefix(f.Name, "__") || hasRequiredArgument(f) {
+			continue
+		}
+		if _, deprecated := deprecationReason(f.Directives); deprecated {
+			continue
+		}
+		fieldDef := schema.Types[f.Type.Name()]
+		if fieldDef == nil || ignored.IgnoresField(def.Name, f.Name) || ignored.IgnoresType(fieldDef.Name) {
+			continue
+		}
+		switch fieldDef.Kind {
+		case ast.Scalar, ast.Enum:
+			selectionSet = append(selectionSet, &ast.Field{Name: f.Name})
+		case ast.Object, ast.Interface:
+			if nested := scalarSelection(schema, fieldDef, depth-1); len(nested) > 0 {
+				selectionSet = append(selectionSet, &ast.Field{Name: f.Name, SelectionSet: nested})
+			}
+		}
+	}
+	return selectionSet
+}
+
+func hasRequiredArgument(f *ast.FieldDefinition) bool {
+	for _, arg := range f.Arguments {
+		if arg.Type.NonNull && arg.DefaultValue == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// usedVariables returns the variable definitions of op referenced inside
+// selectionSet, arguments and directives, following fragment spreads.
+func usedVariables(op *ast.OperationDefinition, selectionSet ast.SelectionSet) (vars ast.VariableDefinitionList) {
+	names := map[string]bool{}
+	spread := map[string]bool{}
+	var walk func(ast.SelectionSet)
+	var walkValue func(*ast.Value)
+	walkValue = func(value *ast.Value) {
+		if value == nil {
+			return
+		}
+		if value.Kind == ast.Variable {
+			names[value.Raw] = true
+		}
+		for _, child := range value.Children {
+			walkValue(child.Value)
+		}
+	}
+	walkDirectives := func(directives ast.DirectiveList) {
+		for _, directive := range directives {
+			for _, arg := range directive.Arguments {
+				walkValue(arg.Value)
+			}
+		}
+	}
+	walk = func(selectionSet ast.SelectionSet) {
+		for _, sel := range selectionSet {
+			switch s := sel.(type) {
+			case *ast.Field:
+				for _, arg := range s.Arguments {
+					walkValue(arg.Value)
+				}
+				walkDirectives(s.Directives)
+				walk(s.SelectionSet)
+			case *ast.InlineFragment:
+				walkDirectives(s.Directives)
+				walk(s.SelectionSet)
+			case *ast.FragmentSpread:
+				walkDirectives(s.Directives)
+				if s.Definition != nil && !spread[s.Name] {
+					spread[s.Name] = true
+					walk(s.Definition.SelectionSet)
+				}
+			}
+		}
+	}
+	walk(selectionSet)
+
+	for _, vd := range op.VariableDefinitions {
+		if names[vd.Variable] {
+			vars = append(vars, vd)
+		}
+	}
+	return
+}
+
+// QueryResolverStubs returns a stub per root field selected by the
+// operations of a validated query document. Fields whose selection cannot be
+// resolved, e.g. spreading fragments of another file, select the scalars.
+func QueryResolverStubs(schema *ast.Schema, queryDoc *ast.QueryDocument) (stubs []ResolverStub) {
+	for _, op := range queryDoc.Operations {
+		for _, sel := range op.SelectionSet {
+			field, ok := sel.(*ast.Field)
+			if !ok || field.Definition == nil {
+				continue
+			}
+			stub := ResolverStub{
+				Operation:    op.Operation,
+				Field:        field.Definition,
+				SelectionSet: field.SelectionSet,
+				Variables:    usedVariables(op, field.SelectionSet),
+			}
+			if len(inlineFragmentSpreads(field.SelectionSet)) == 0 {
+				stub.SelectionSet = scalarSelection(schema, schema.Types[field.Definition.Type.Name()], 1)
+				stub.Variables = nil
+			}
+			stubs = append(stubs, stub)
+		}
+	}
+	return
+}
+
+// WriteResolvers appends a resolver stub and its Hasura document per root
+// field to the Go file at path. Stubs already present in the file are left
+// untouched; the package is named after the file directory.
+func WriteResolvers(path string, schema *ast.Schema, stubs []ResolverStub) {
+	existing := ""
+	if content, err := os.ReadFile(path); err == nil {
+		existing = string(content)
+	} else if !os.IsNotExist(err) {
+		fatalf("cannot read %s: %v", path, err)
+	}
+
+	mapper := newGoTypeMapper(schema)
+	var body strings.Builder
+	added := 0
+	sort.SliceStable(stubs, func(i, j int) bool {
+		return stubs[i].Field.Name < stubs[j].Field.Name
+	})
+	for _, stub := range stubs {
+		receiver := string(stub.Operation) + "Resolver"
+		method := goName(stub.Field.Name)
+		signature := "func (r *" + receiver + ") " + method + "("
+		if strings.Contains(existing, signature) || strings.Contains(body.String(), signature) {
+			continue
+		}
+
+		docConst := goArgName(stub.Field.Name) + "Document"
+		returnType := mapper.goType(stub.Field.Type)
+
+		params := []string{"ctx context.Context"}
+		var vars []string
+		for _, arg := range stub.Field.Arguments {
+			params = append(params, goArgName(arg.Name)+" "+mapper.argType(arg.Type))
+			vars = append(vars, fmt.Sprintf("%q: %s,", arg.Name, goArgName(arg.Name)))
+		}
+		// the UI variables of the selection, e.g. @include(if: $withLot), are
+		// not arguments, forward them from the incoming operation
+		for _, vd := range stub.Variables {
+			if stub.Field.Arguments.ForName(vd.Variable) == nil {
+				vars = append(vars, fmt.Sprintf("%q: graphql.GetOperationContext(ctx).Variables[%q],", vd.Variable, vd.Variable))
+				mapper.imports["github.com/99designs/gqlgen/graphql"] = true
+			}
+		}
+
+		fmt.Fprintf(&body, "\nconst %s = `\n%s`\n", docConst, HasuraDocument(stub))
+		fmt.Fprintf(&body, "\n// %s forwards %s %s to Hasura.\n", method, stub.Operation, stub.Field.Name)
+		fmt.Fprintf(&body, "%s%s) (%s, error) {\n", signature, strings.Join(params, ", "), returnType)
+		fmt.Fprintf(&body, "var resp struct {\nData %s `json:%q`\n}\n", returnType, stub.Field.Name)
+		fmt.Fprintf(&body, "err := r.Hasura.Do(ctx, %s, map[string]interface{}{\n%s\n}, &resp)\n", docConst, strings.Join(vars, "\n"))
+		fmt.Fprintf(&body, "return resp.Data, err\n}\n")
+		added++
+	}
+
+	header := "// Resolver stubs generated by gqlsch, forwarding to Hasura through\n" +
+		"// Resolver.Hasura.Do(ctx, query, variables, resp). Edit freely.\n"
+	mapper.imports["context"] = true
+	writeGoSource(path, existing, header, mapper.imports, body.String())
+
+	fmt.Fprintln(stdout, "wrote", added, "resolver stubs to", path)
+}
+
+// writeGoSource appends body to the existing Go source of path, or creates it
+// with header and imports in a package named after the file directory.
+func writeGoSource(path, existing, header string, imports map[string]bool, body string) {
+	var pkgPaths []string
+	for pkgPath := range imports {
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+	sort.Strings(pkgPaths)
+
+	var src strings.Builder
+	if existing == "" {
+		fmt.Fprintf(&src, "package %s\n\n%s\n", filepath.Base(filepath.Dir(path)), header)
+		if len(pkgPaths) > 0 {
+			src.WriteString("import (\n")
+			// standard library first, the module paths start with a domain
+			for _, std := range []bool{true, false} {
+				for _, pkgPath := range pkgPaths {
+					if isStd := !strings.Contains(strings.Split(pkgPath, "/")[0], "."); isStd == std {
+						fmt.Fprintf(&src, "%q\n", pkgPath)
+					}
+				}
+				src.WriteString("\n")
+			}
+			src.WriteString(")\n")
+		}
+	} else {
+		src.WriteString(existing)
+		for _, pkgPath := range pkgPaths {
+			if !strings.Contains(existing, fmt.Sprintf("%q", pkgPath)) {
+				warnf("%s may need to import %q", path, pkgPath)
+			}
+		}
+	}
+	src.WriteString(body)
+
+	formatted, err := format.Source([]byte(src.String()))
+	if err != nil {
+		fatalf("cannot format %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, formatted, 0o644); err != nil {
+		fatalf("cannot write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// Renames maps the old names of the raw schema to the new wms-graph ones.
+type Renames struct {
+	// Fields renames root fields, old=new
+	Fields map[string]string
+	// Args renames root field arguments, keyed by old field.arg
+	Args map[string]string
+	// Types renames input types used in variable definitions
+	Types map[string]string
+}
+
+// parseRenamesFile reads lines of field:old=new, arg:field.old=new or
+// type:old=new, skipping blank lines and # comments.
+func parseRenamesFile(filePath string) Renames {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		panic("⚠️ Error reading renames file" + filePath + ":" + err.Error())
+	}
+
+	renames := Renames{Fields: map[string]string{}, Args: map[string]string{}, Types: map[string]string{}}
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		kind, rename, _ := strings.Cut(line, ":")
+		oldName, newName, ok := strings.Cut(rename, "=")
+		oldName, newName = strings.TrimSpace(oldName), strings.TrimSpace(newName)
+		if !ok || oldName == "" || newName == "" {
+			fatalf("%s:%d: the format must be field:old=new, arg:field.old=new or type:old=new", filePath, i+1)
+		}
+
+		switch kind {
+		case "field":
+			renames.Fields[oldName] = newName
+		case "arg":
+			renames.Args[oldName] = newName
+		case "type":
+			renames.Types[oldName] = newName
+		default:
+			fatalf("%s:%d: unknown rename kind %s", filePath, i+1, kind)
+		}
+	}
+
+	return renames
+}
+
+// textEdit replaces the bytes [Start, End) of a source file.
+type textEdit struct {
+	Start, End int
+	Text       string
+}
+
+// RewriteSource applies renames to the gql literals of a source file and
+// returns the new content along with the number of renamed names. The
+// literals are edited in place, keeping formatting and interpolations. Root
+// fields keep their response key through an alias so the UI code reading
+// the result does not change.
+func RewriteSource(path, content string, renames Renames) (string, int) {
+	var edits []textEdit
+
+	for _, doc := range extractGQLFromContent(path, content) {
+		queryDoc, err := parser.ParseQuery(&ast.Source{Input: doc.Body, Name: path})
+		if err != nil {
+			warnf("%s, not rewritten", doc.describeError(err))
+			continue
+		}
+
+		runeOffsets := runeByteOffsets(doc.Body)
+		replace := func(start int, oldName, text string) {
+			from := runeOffsets[start]
+			// the name follows the alias of aliased fields
+			idx := nameIndex(doc.Body[from:], oldName)
+			if idx == -1 {
+				return
+			}
+			from += idx
+			edits = append(edits, textEdit{Start: doc.Offset + from, End: doc.Offset + from + len(oldName), Text: text})
+		}
+
+		for _, op := range queryDoc.Operations {
+			for _, vd := range op.VariableDefinitions {
+				t := vd.Type
+				for t.Elem != nil {
+					t = t.Elem
+				}
+				if newName, exist := renames.Types[t.NamedType]; exist {
+					replace(t.Position.Start, t.NamedType, newName)
+				}
+			}
+
+			for _, sel := range op.SelectionSet {
+				field, ok := sel.(*ast.Field)
+				if !ok {
+					continue
+				}
+				for _, arg := range field.Arguments {
+					if newName, exist := renames.Args[field.Name+"."+arg.Name]; exist {
+						replace(arg.Position.Start, arg.Name, newName)
+					}
+				}
+				if newName, exist := renames.Fields[field.Name]; exist {
+					text := newName
+					if field.Alias == field.Name {
+						text = field.Name + ": " + newName
+					}
+					replace(field.Position.Start, field.Name, text)
+				}
+			}
+		}
+	}
+
+	// apply from the end so earlier offsets stay valid
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Start > edits[j].Start
+	})
+	for _, e := range edits {
+		content = content[:e.Start] + e.Text + content[e.End:]
+	}
+
+	return content, len(edits)
+}
+
+// runeByteOffsets maps the rune offsets used by ast.Position to byte offsets.
+func runeByteOffsets(s string) []int {
+	offsets := make([]int, 0, len(s)+1)
+	for i := range s {
+		offsets = append(offsets, i)
+	}
+	return append(offsets, len(s))
+}
+
+// nameIndex returns the byte index of the first whole-word name in s.
+func nameIndex(s, name string) int {
+	for from := 0; ; {
+		idx := strings.Index(s[from:], name)
+		if idx == -1 {
+			return -1
+		}
+		idx += from
+		end := idx + len(name)
+		if (idx == 0 || !isNameByte(s[idx-1])) && (end == len(s) || !isNameByte(s[end])) {
+			return idx
+		}
+		from = end
+	}
+}
+
+func isNameByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func rewriteSources(sourcePath, renamesFilePath string) {
+	renames := parseRenamesFile(renamesFilePath)
+
+	var rewritten []string
+	total := 0
+	for _, file := range readSourceFiles(sourcePath) {
+		content, count := RewriteSource(file.Path, file.Content, renames)
+		if count == 0 {
+			continue
+		}
+		if err := os.WriteFile(file.Path, []byte(content), 0o644); err != nil {
+			fatalf("cannot write %s: %v", file.Path, err)
+		}
+		fmt.Fprintln(stdout, "rewrote", count, "names in", file.Path)
+		rewritten = append(rewritten, file.Path)
+		total += count
+	}
+	recordResults(rewritten)
+
+	fmt.Fprintf(stdout, "\n-------\n\n")
+	fmt.Fprintln(stdout, "rewrite:", total, "names in", len(rewritten), "files")
+}
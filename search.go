@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/agnivade/levenshtein"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// maxSearchResults bounds the search command output.
+const maxSearchResults = 50
+
+// SearchResult is a schema type, root field or type field matching a search.
+type SearchResult struct {
+	// Kind is type, query, mutation, subscription or field
+	Kind string `json:"kind"`
+	// Name is the type name, the root field name or Type.field
+	Name string `json:"name"`
+	// Type is the kind of a type or the type of a field
+	Type string `json:"type"`
+	// Distance is the edit distance to the search, 0 when it contains it
+	Distance int `json:"distance"`
+}
+
+// matchDistance returns the edit distance between term and name, 0 when name
+// contains term, ignoring case. ok is false when name is too far from term.
+func matchDistance(term, name string) (int, bool) {
+	term, name = strings.ToLower(term), strings.ToLower(name)
+	if strings.Contains(name, term) {
+		return 0, true
+	}
+
+	distance := levenshtein.ComputeDistance(term, name)
+	return distance, distance <= max(2, len(term)/3)
+}
+
+// Search returns the types, root fields and type fields whose name contains
+// term or is a few edits away from it, the closest first.
+func Search(schema *ast.Schema, term string) []SearchResult {
+	roots := map[string]string{}
+	for op, def := range map[string]*ast.Definition{"query": schema.Query, "mutation": schema.Mutation, "subscription": schema.Subscription} {
+		if def != nil {
+			roots[def.Name] = op
+		}
+	}
+
+	var results []SearchResult
+	for _, def := range schema.Types {
+		if def.BuiltIn || strings.HasPrefix(def.Name, "__") || ignored.IgnoresType(def.Name) {
+			continue
+		}
+		if distance, ok := matchDistance(term, def.Name); ok {
+			results = append(results, SearchResult{Kind: "type", Name: def.Name, Type: kindName(def.Kind), Distance: distance})
+		}
+
+		for _, f := range keptFields(def) {
+			if strings.HasPrefix(f.Name, "__") {
+				continue
+			}
+			distance, ok := matchDistance(term, f.Name)
+			if !ok {
+				continue
+			}
+			if op, root := roots[def.Name]; root {
+				results = append(results, SearchResult{Kind: op, Name: f.Name, Type: f.Type.String(), Distance: distance})
+			} else {
+				results = append(results, SearchResult{Kind: "field", Name: def.Name + "." + f.Name, Type: f.Type.String(), Distance: distance})
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Distance != results[j].Distance {
+			return results[i].Distance < results[j].Distance
+		}
+		if len(results[i].Name) != len(results[j].Name) {
+			return len(results[i].Name) < len(results[j].Name)
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+// didYouMean returns a " (did you mean ...?)" hint listing the candidates
+// closest to name, or an empty string when none is closest.
+func didYouMean(name string, candidates []string) string {
+	type scored struct {
+		name     string
+		distance int
+	}
+	var closest []scored
+	for _, c := range candidates {
+		distance := levenshtein.ComputeDistance(strings.ToLower(name), strings.ToLower(c))
+		if distance <= max(2, len(name)/3) {
+			closest = append(closest, scored{c, distance})
+		}
+	}
+	if len(closest) == 0 {
+		return ""
+	}
+
+	sort.Slice(closest, func(i, j int) bool {
+		if closest[i].distance != closest[j].distance {
+			return closest[i].distance < closest[j].distance
+		}
+		return closest[i].name < closest[j].name
+	})
+	var names []string
+	for i := 0; i < len(closest) && i < 3; i++ {
+		names = append(names, closest[i].name)
+	}
+	return " (did you mean " + strings.Join(names, ", ") + "?)"
+}
+
+// typeNames returns the names of the types of schema, introspection and
+// built-in types left out.
+func typeNames(schema *ast.Schema) (names []string) {
+	for name, def := range schema.Types {
+		if !def.BuiltIn && !strings.HasPrefix(name, "__") {
+			names = append(names, name)
+		}
+	}
+	return
+}
+
+// fieldNames returns the field names of def.
+func fieldNames(def *ast.Definition) (names []string) {
+	for _, f := range def.Fields {
+		if !strings.HasPrefix(f.Name, "__") {
+			names = append(names, f.Name)
+		}
+	}
+	return
+}
+
+func reportSearch(schemaFilePath, term string) {
+	// Load schema
+	schema := LoadSchema(schemaFilePath)
+
+	results := Search(schema, term)
+	if len(results) > maxSearchResults {
+		warnf("%d matches, only the closest %d are listed", len(results), maxSearchResults)
+		results = results[:maxSearchResults]
+	}
+	recordResults(results)
+
+	for _, r := range results {
+		fmt.Fprintf(stdout, "%s %s: %s\n", r.Kind, r.Name, r.Type)
+	}
+
+	fmt.Fprintf(stdout, "\n-------\n\n")
+	fmt.Fprintln(stdout, "search:", len(results), "matches for", term)
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const tuiHelp = `keys:
+  type text         filter the types by name, live
+  up/down, j/k      move the cursor, pgup/pgdn by page
+  enter, right, l   open the type, or the type of the field
+  left, h, bksp     return to the previous type, or to the type filter
+  space, m          mark or unmark the field
+  /                 filter the types again
+  t                 emit the trimmed types of the marked fields
+  e                 emit a query or fragment document of the marked fields
+  ?                 print this help
+  esc, q            leave the text view, or the explorer
+  ctrl-c            leave
+
+the emitted documents are printed again once the explorer is left`
+
+type tuiMode int
+
+const (
+	tuiFind tuiMode = iota
+	tuiType
+	tuiText
+)
+
+// tui is the full screen explorer, drawn over the state of the line one.
+type tui struct {
+	e    *explorer
+	buf  *bytes.Buffer
+	mode tuiMode
+	// back is the mode the text view returns to
+	back    tuiMode
+	filter  string
+	matches []string
+	// heading and text are shown by the text view
+	heading string
+	text    []string
+	// cursor and offset are the selected and first shown rows, cursors the
+	// ones of the types left open behind the current one
+	cursor  int
+	offset  int
+	cursors []int
+	message string
+	emitted []string
+}
+
+// exploreTerminal runs the full screen explorer, reading keys from in and
+// drawing on out at the size returned by size, until it is left or the
+// input ends. It returns the documents emitted during the session.
+func exploreTerminal(schema *ast.Schema, in io.Reader, out io.Writer, size func() (int, int)) []string {
+	buf := &bytes.Buffer{}
+	t := &tui{e: &explorer{schema: schema, out: buf, marks: map[string]map[string]bool{}}, buf: buf}
+	t.setFilter("")
+
+	keys := bufio.NewReader(in)
+	for {
+		width, height := size()
+		fmt.Fprint(out, "\x1b[H\x1b[2J"+strings.Join(t.render(width, height), "\r\n"))
+
+		key, err := readKey(keys)
+		if err != nil || !t.handle(key) {
+			return t.emitted
+		}
+	}
+}
+
+// readKey returns the next key of in, escape sequences named up, down,
+// right, left, pgup and pgdn.
+func readKey(in *bufio.Reader) (string, error) {
+	r, _, err := in.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	// a lone escape is not followed by the rest of a sequence
+	if next, err := in.Peek(1); r != '\x1b' || err != nil || (next[0] != '[' && next[0] != 'O') {
+		return string(r), nil
+	}
+
+	var seq []byte
+	for {
+		b, err := in.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		seq = append(seq, b)
+		if len(seq) > 1 && (b >= 'A' && b <= 'Z' || b == '~') {
+			break
+		}
+	}
+	switch string(seq) {
+	case "[A", "OA":
+		return "up", nil
+	case "[B", "OB":
+		return "down", nil
+	case "[C", "OC":
+		return "right", nil
+	case "[D", "OD":
+		return "left", nil
+	case "[5~":
+		return "pgup", nil
+	case "[6~":
+		return "pgdn", nil
+	}
+	return "", nil
+}
+
+// handle applies key, it returns false once the explorer is left.
+func (t *tui) handle(key string) bool {
+	t.message = ""
+	if key == "\x03" {
+		return false
+	}
+
+	switch key {
+	case "up":
+		t.move(-1)
+		return true
+	case "down":
+		t.move(1)
+		return true
+	case "pgup":
+		t.move(-10)
+		return true
+	case "pgdn":
+		t.move(10)
+		return true
+	}
+
+	switch t.mode {
+	case tuiFind:
+		switch key {
+		case "\x1b":
+			if len(t.e.stack) == 0 {
+				return false
+			}
+			t.showType(t.cursorOf(len(t.e.stack)))
+		case "\r", "\n", "right":
+			if len(t.matches) > 0 {
+				t.e.stack = nil
+				t.cursors = nil
+				t.e.stack = append(t.e.stack, exploreFrame{Type: t.matches[t.cursor]})
+				t.showType(0)
+			}
+		case "\x7f", "\b":
+			if t.filter != "" {
+				runes := []rune(t.filter)
+				t.setFilter(string(runes[:len(runes)-1]))
+			}
+		default:
+			if r := []rune(key); len(r) == 1 && r[0] >= ' ' {
+				t.setFilter(t.filter + key)
+			}
+		}
+	case tuiType:
+		switch key {
+		case "q", "\x1b":
+			return false
+		case "j":
+			t.move(1)
+		case "k":
+			t.move(-1)
+		case "\r", "\n", "right", "l":
+			t.follow()
+		case "left", "h", "\x7f", "\b":
+			if len(t.e.stack) > 1 {
+				t.e.stack = t.e.stack[:len(t.e.stack)-1]
+				t.showType(t.cursors[len(t.e.stack)-1])
+			} else {
+				t.mode = tuiFind
+				t.setFilter(t.filter)
+			}
+		case " ", "m":
+			if fields := t.fields(); len(fields) > 0 {
+				t.e.toggleMark(fields[t.cursor].Name)
+			}
+		case "/":
+			t.mode = tuiFind
+			t.setFilter("")
+		case "t":
+			t.emit("type")
+		case "e":
+			t.emit("query")
+		case "?":
+			t.showText("help", strings.Split(tuiHelp, "\n"))
+		}
+	case tuiText:
+		switch key {
+		case "q", "\x1b", "left", "h", "\r", "\n":
+			t.mode = t.back
+			t.cursor, t.offset = 0, 0
+			if t.mode == tuiType {
+				t.showType(t.cursorOf(len(t.e.stack)))
+			}
+		case "j":
+			t.move(1)
+		case "k":
+			t.move(-1)
+		}
+	}
+	return true
+}
+
+// cursorOf returns the cursor to restore when showing the type at depth n of
+// the stack again, the current one being kept while another view is shown.
+func (t *tui) cursorOf(n int) int {
+	if len(t.cursors) >= n {
+		return t.cursors[n-1]
+	}
+	return 0
+}
+
+func (t *tui) setFilter(filter string) {
+	t.filter = filter
+	t.matches = t.e.matchTypes(filter)
+	t.cursor, t.offset = 0, 0
+}
+
+// showType shows the current type with the cursor on row cursor, keeping it
+// so the other views can return to it.
+func (t *tui) showType(cursor int) {
+	t.mode = tuiType
+	t.cursor, t.offset = cursor, 0
+	t.cursors = append(t.cursors[:len(t.e.stack)-1], cursor)
+}
+
+func (t *tui) showText(heading string, lines []string) {
+	if t.mode != tuiText {
+		t.back = t.mode
+	}
+	t.mode = tuiText
+	t.heading, t.text = heading, lines
+	t.cursor, t.offset = 0, 0
+}
+
+// follow opens the type of the field under the cursor.
+func (t *tui) follow() {
+	fields := t.fields()
+	if len(fields) == 0 {
+		return
+	}
+	field := fields[t.cursor]
+	if def := t.e.schema.Types[field.Type.Name()]; def == nil || def.BuiltIn {
+		t.message = field.Name + " is a " + field.Type.String()
+		return
+	}
+	t.e.stack = append(t.e.stack, exploreFrame{Type: field.Type.Name(), Field: field.Name})
+	t.showType(0)
+}
+
+func (t *tui) emit(what string) {
+	if len(t.e.marks) == 0 {
+		t.message = "no marked field, mark fields with space"
+		return
+	}
+
+	t.buf.Reset()
+	t.e.emit(what)
+	emitted := strings.TrimRight(t.buf.String(), "\n")
+	t.emitted = append(t.emitted, emitted)
+	t.showText("emit "+what, strings.Split(emitted, "\n"))
+}
+
+func (t *tui) fields() ast.FieldList {
+	return t.e.fields(t.e.schema.Types[t.e.stack[len(t.e.stack)-1].Type])
+}
+
+// rows returns the number of rows the cursor moves over.
+func (t *tui) rows() int {
+	switch t.mode {
+	case tuiFind:
+		return len(t.matches)
+	case tuiType:
+		return len(t.fields())
+	}
+	return len(t.text)
+}
+
+func (t *tui) move(delta int) {
+	t.cursor = max(0, min(t.cursor+delta, t.rows()-1))
+	if t.mode == tuiType {
+		t.cursors[len(t.e.stack)-1] = t.cursor
+	}
+}
+
+// render returns the lines of the screen: a title, the rows around the
+// cursor, the details of the selected row and the status line.
+func (t *tui) render(width, height int) []string {
+	title, header, detail, status := "gqlsch explore", "", "", t.message
+	var rows []string
+	switch t.mode {
+	case tuiFind:
+		header = "find: " + t.filter + "_"
+		for _, name := range t.matches {
+			rows = append(rows, kindName(t.e.schema.Types[name].Kind)+" "+name)
+		}
+		if len(t.matches) > 0 {
+			detail = t.e.schema.Types[t.matches[t.cursor]].Description
+		}
+		if status == "" {
+			status = fmt.Sprintf("%d types, type to filter, enter open, esc leave", len(t.matches))
+		}
+	case tuiType:
+		title += "  " + strings.TrimSuffix(t.e.prompt(), " > ")
+		def := t.e.schema.Types[t.e.stack[len(t.e.stack)-1].Type]
+		header = kindName(def.Kind) + " " + def.Name
+		if def.Description != "" {
+			header += " # " + def.Description
+		}
+		for _, v := range def.EnumValues {
+			rows = append(rows, "    "+v.Name)
+		}
+		marked := t.e.marks[t.e.stack[0].Type]
+		fields := t.fields()
+		for _, f := range fields {
+			mark := "[ ] "
+			if marked[t.e.path(f.Name)] {
+				mark = "[x] "
+			}
+			rows = append(rows, mark+fieldSignature(f))
+		}
+		if len(fields) > 0 {
+			detail = fieldSignature(fields[t.cursor])
+			if fields[t.cursor].Description != "" {
+				detail += " # " + fields[t.cursor].Description
+			}
+		}
+		if status == "" {
+			status = "enter open, left back, space mark, / find, t emit type, e emit query, ? help, q quit"
+		}
+	case tuiText:
+		header, rows = t.heading, t.text
+		if status == "" {
+			status = "up/down scroll, esc back"
+		}
+	}
+
+	// the title, header, separator, detail and status lines frame the rows,
+	// the text view scrolls with its cursor on the first row
+	listHeight := max(1, height-5)
+	if t.mode == tuiText {
+		t.offset = t.cursor
+	} else if t.cursor < t.offset {
+		t.offset = t.cursor
+	} else if t.cursor >= t.offset+listHeight {
+		t.offset = t.cursor - listHeight + 1
+	}
+
+	lines := []string{"\x1b[1m" + fitWidth(title, width) + "\x1b[0m", fitWidth(header, width)}
+	for i := t.offset; i < t.offset+listHeight; i++ {
+		switch {
+		case i >= len(rows):
+			lines = append(lines, "")
+		case i == t.cursor && t.mode != tuiText:
+			lines = append(lines, "\x1b[7m"+fitWidth(rows[i], width)+"\x1b[0m")
+		default:
+			lines = append(lines, fitWidth(rows[i], width))
+		}
+	}
+	lines = append(lines, strings.Repeat("-", width), fitWidth(strings.ReplaceAll(detail, "\n", " "), width), fitWidth(status, width))
+	return lines
+}
+
+// fitWidth cuts s to width runes.
+func fitWidth(s string, width int) string {
+	if runes := []rune(s); len(runes) > width {
+		return string(runes[:width])
+	}
+	return s
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var tsBuiltinScalars = map[string]string{
+	"Int":     "number",
+	"Float":   "number",
+	"String":  "string",
+	"Boolean": "boolean",
+	"ID":      "string",
+}
+
+// tsGenerator writes the TypeScript result and variables types of operations,
+// along with the input types their variables refer to.
+type tsGenerator struct {
+	schema *ast.Schema
+	sb     strings.Builder
+	inputs map[string]bool
+	// pending input types, written after the operations
+	pending []string
+}
+
+// GenerateTypeScript returns the <Name>Query/<Name>Mutation result types and
+// their <Name>QueryVariables counterparts for the named operations of docs.
+// Fragment spreads are resolved against the fragments of all docs.
+func GenerateTypeScript(schema *ast.Schema, docs []GQLDocument) string {
+	g := &tsGenerator{schema: schema, inputs: map[string]bool{}}
+
+	for _, doc := range withFragments(docs) {
+		queryDoc, errs := ValidateQuery(schema, doc.Body)
+		if queryDoc == nil {
+			reportValidation(errs, doc)
+			continue
+		}
+		recordOperations(doc, queryDoc)
+
+		for _, op := range queryDoc.Operations {
+			if !doc.owns(op.Position) {
+				continue
+			}
+			if op.Name == "" {
+				warnf("%s: anonymous %s skipped, name it to get types", doc.Position(op.Position.Line, op.Position.Column), op.Operation)
+				continue
+			}
+			g.operation(op)
+		}
+	}
+
+	sort.Strings(g.pending)
+	for len(g.pending) > 0 {
+		name := g.pending[0]
+		g.pending = g.pending[1:]
+		g.input(g.schema.Types[name])
+	}
+
+	return g.sb.String()
+}
+
+func (g *tsGenerator) operation(op *ast.OperationDefinition) {
+	// GetStockQuery stays GetStockQuery, as codegen does
+	typeName := op.Name
+	if suffix := strings.ToUpper(string(op.Operation)[:1]) + string(op.Operation)[1:]; !strings.HasSuffix(typeName, suffix) {
+		typeName += suffix
+	}
+
+	fmt.Fprintf(&g.sb, "export type %s = %s;\n\n", typeName, g.selectionType(op.SelectionSet, ""))
+
+	if len(op.VariableDefinitions) == 0 {
+		fmt.Fprintf(&g.sb, "export type %sVariables = {};\n\n", typeName)
+		return
+	}
+
+	fmt.Fprintf(&g.sb, "export type %sVariables = {\n", typeName)
+	for _, vd := range op.VariableDefinitions {
+		optional := ""
+		if !vd.Type.NonNull || vd.DefaultValue != nil {
+			optional = "?"
+		}
+		fmt.Fprintf(&g.sb, "  %s%s: %s;\n", vd.Variable, optional, g.inputType(vd.Type))
+	}
+	g.sb.WriteString("};\n\n")
+}
+
+// selectionType returns the object type of a selection set, fields selected
+// several times (directly or through fragments) being merged under their
+// response name. Fields of fragments on another type are optional.
+func (g *tsGenerator) selectionType(selectionSet ast.SelectionSet, indent string) string {
+	var keys []string
+	fields := map[string][]*ast.Field{}
+	optional := map[string]bool{}
+
+	var collect func(ast.SelectionSet, *ast.Definition, bool)
+	collect = func(selectionSet ast.SelectionSet, parent *ast.Definition, conditional bool) {
+		for _, sel := range selectionSet {
+			switch s := sel.(type) {
+			case *ast.Field:
+				if s.Definition == nil {
+					continue
+				}
+				key := s.Alias
+				if key == "" {
+					key = s.Name
+				}
+				if _, exist := fields[key]; !exist {
+					keys = append(keys, key)
+					optional[key] = conditional
+				}
+				optional[key] = optional[key] && conditional
+				fields[key] = append(fields[key], s)
+				if parent == nil {
+					parent = s.ObjectDefinition
+				}
+			case *ast.InlineFragment:
+				collect(s.SelectionSet, parent, conditional || (parent != nil && s.TypeCondition != "" && s.TypeCondition != parent.Name))
+			case *ast.FragmentSpread:
+				if s.Definition != nil {
+					collect(s.Definition.SelectionSet, parent, conditional || (parent != nil && s.Definition.TypeCondition != parent.Name))
+				}
+			}
+		}
+	}
+	collect(selectionSet, nil, false)
+
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for _, key := range keys {
+		same := fields[key]
+		var merged ast.SelectionSet
+		for _, f := range same {
+			merged = append(merged, f.SelectionSet...)
+		}
+		field := same[0]
+
+		opt := ""
+		if optional[key] {
+			opt = "?"
+		}
+		fieldType := g.wrap(field.Definition.Type, func(name string) string {
+			def := g.schema.Types[name]
+			if def != nil && (def.Kind == ast.Object || def.Kind == ast.Interface || def.Kind == ast.Union) {
+				return g.selectionType(merged, indent+"  ")
+			}
+			return g.leafType(name)
+		})
+		// the validator defines __typename as nullable, it never is
+		if field.Name == "__typename" {
+			fieldType = "string"
+		}
+		fmt.Fprintf(&sb, "%s  %s%s: %s;\n", indent, key, opt, fieldType)
+	}
+	sb.WriteString(indent + "}")
+
+	return sb.String()
+}
+
+// wrap applies the list and nullability modifiers of t around its named type.
+func (g *tsGenerator) wrap(t *ast.Type, named func(string) string) string {
+	var ts string
+	if t.Elem != nil {
+		ts = "Array<" + g.wrap(t.Elem, named) + ">"
+	} else {
+		ts = named(t.NamedType)
+	}
+	if !t.NonNull {
+		ts += " | null"
+	}
+	return ts
+}
+
+func (g *tsGenerator) leafType(name string) string {
+	if ts, exist := tsBuiltinScalars[name]; exist {
+		return ts
+	}
+
+	def := g.schema.Types[name]
+	if def != nil && def.Kind == ast.Enum {
+		var values []string
+		for _, v := range def.EnumValues {
+			values = append(values, "'"+v.Name+"'")
+		}
+		return strings.Join(values, " | ")
+	}
+
+	// custom scalars such as uuid or timestamptz travel as json
+	return "any"
+}
+
+func (g *tsGenerator) inputType(t *ast.Type) string {
+	return g.wrap(t, func(name string) string {
+		def := g.schema.Types[name]
+		if def != nil && def.Kind == ast.InputObject {
+			if !g.inputs[name] {
+				g.inputs[name] = true
+				g.pending = append(g.pending, name)
+			}
+			return name
+		}
+		return g.leafType(name)
+	})
+}
+
+func (g *tsGenerator) input(def *ast.Definition) {
+	fmt.Fprintf(&g.sb, "export type %s = {\n", def.Name)
+	for _, f := range def.Fields {
+		optional := ""
+		if !f.Type.NonNull || f.DefaultValue != nil {
+			optional = "?"
+		}
+		fmt.Fprintf(&g.sb, "  %s%s: %s;\n", f.Name, optional, g.inputType(f.Type))
+	}
+	g.sb.WriteString("};\n\n")
+}
+
+func reportTypeScript(schemaFilePath, sourcePath string) {
+	// Load schema
+	schema := LoadSchema(schemaFilePath)
+
+	ts := GenerateTypeScript(schema, extractGQLFromPath(sourcePath))
+	recordResults(ts)
+
+	fmt.Fprint(stdout, ts)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// ValidateQuery parses gqlQuery and validates it against schema. Unlike
+// gqlparser.LoadQuery the parsed document is returned alongside the
+// validation errors, it is nil only when the query cannot be parsed.
+func ValidateQuery(schema *ast.Schema, gqlQuery string) (*ast.QueryDocument, gqlerror.List) {
+	queryDoc, err := parser.ParseQuery(&ast.Source{Input: gqlQuery, Name: "query.graphql"})
+	if err != nil {
+		if gqlErr, ok := err.(*gqlerror.Error); ok {
+			return nil, gqlerror.List{gqlErr}
+		}
+		return nil, gqlerror.List{gqlerror.Wrap(err)}
+	}
+
+	return queryDoc, validator.Validate(schema, queryDoc)
+}
+
+// reportValidation prints every validation error at its position in the
+// source file doc was extracted from. It returns the number of reported errors.
+func reportValidation(errs gqlerror.List, doc GQLDocument) int {
+	for _, e := range errs {
+		warnf("%s: %s", doc.ErrorPosition(e), e.Message)
+	}
+
+	return len(errs)
+}
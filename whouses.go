@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// hookExportRegex matches the hooks a source file exports
+var hookExportRegex = regexp.MustCompile(`export\s+(?:default\s+)?(?:const|function)\s+(use\w+)`)
+
+// Usage is a UI operation selecting a given type or field, with the hook
+// files wrapping the operation and the files (pages) importing those hooks.
+type Usage struct {
+	Operation string   `json:"operation"`
+	Position  string   `json:"position"`
+	Hooks     []string `json:"hooks"`
+	Pages     []string `json:"pages"`
+}
+
+// WhoUses scans the sources below sourcePath for operations selecting
+// typeName, or only its fieldName when given. Fragment spreads are resolved
+// against the fragments defined anywhere in the sources.
+func WhoUses(schema *ast.Schema, sourcePath, typeName, fieldName string) []Usage {
+	files := readSourceFiles(sourcePath)
+
+	var docs []GQLDocument
+	queryDocs := map[int]*ast.QueryDocument{}
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, file := range files {
+		for _, doc := range extractGQLFromContent(file.Path, file.Content) {
+			queryDoc, err := parser.ParseQuery(&ast.Source{Input: doc.Body, Name: doc.SourcePath})
+			if err != nil {
+				continue
+			}
+			recordOperations(doc, queryDoc)
+			for _, frag := range queryDoc.Fragments {
+				fragments[frag.Name] = frag
+			}
+			queryDocs[len(docs)] = queryDoc
+			docs = append(docs, doc)
+		}
+	}
+
+	consumers := newConsumerIndex(files)
+
+	var usages []Usage
+	for i, doc := range docs {
+		queryDoc, exist := queryDocs[i]
+		if !exist {
+			continue
+		}
+		for _, op := range queryDoc.Operations {
+			root := operationRoot(schema, op)
+			if root == nil || !selectsType(schema, op.SelectionSet, root, fragments, map[string]bool{}, typeName, fieldName) {
+				continue
+			}
+
+			name := op.Name
+			if name == "" {
+				name = "<anonymous>"
+			}
+			usage := Usage{
+				Operation: string(op.Operation) + " " + name,
+				Position:  doc.Position(op.Position.Line, op.Position.Column),
+			}
+
+			usage.Hooks, usage.Pages = consumers.consumers(doc)
+
+			usages = append(usages, usage)
+		}
+	}
+
+	return usages
+}
+
+// consumerIndex resolves the hook files wrapping a gql document and the page
+// files importing those hooks, the way GetGQLImport and GetImportFromDir do.
+type consumerIndex struct {
+	// gqlImports are the names each file imports from @wms/gqls
+	gqlImports map[string][]string
+	// hookImports are the names each page file imports from @wms/hooks/
+	hookImports map[string][]string
+	// hooks are the use* names exported by each file
+	hooks map[string][]string
+}
+
+func newConsumerIndex(files []sourceFile) *consumerIndex {
+	c := &consumerIndex{gqlImports: map[string][]string{}, hookImports: map[string][]string{}, hooks: map[string][]string{}}
+	for _, file := range files {
+		for _, imp := range importsFrom(file.Content, "@wms/gqls") {
+			c.gqlImports[file.Path] = append(c.gqlImports[file.Path], imp.Name)
+		}
+		if strings.Contains(file.Path, "/pages/") {
+			for _, imp := range importsFrom(file.Content, "@wms/hooks/") {
+				c.hookImports[file.Path] = append(c.hookImports[file.Path], imp.Name)
+			}
+		}
+		for _, match := range hookExportRegex.FindAllStringSubmatch(file.Content, -1) {
+			c.hooks[file.Path] = append(c.hooks[file.Path], match[1])
+		}
+	}
+	return c
+}
+
+// consumers returns the sorted hook and page files using doc.
+func (c *consumerIndex) consumers(doc GQLDocument) (hooks, pages []string) {
+	// hooks either wrap the gql literal in place or import its binding
+	hookFiles := map[string]bool{}
+	if len(c.hooks[doc.SourcePath]) > 0 {
+		hookFiles[doc.SourcePath] = true
+	}
+	for path, names := range c.gqlImports {
+		if doc.Name != "" && containsString(names, doc.Name) && len(c.hooks[path]) > 0 {
+			hookFiles[path] = true
+		}
+	}
+
+	pageFiles := map[string]bool{}
+	for hookFile := range hookFiles {
+		hooks = append(hooks, hookFile)
+		for path, names := range c.hookImports {
+			for _, hook := range c.hooks[hookFile] {
+				if containsString(names, hook) {
+					pageFiles[path] = true
+				}
+			}
+		}
+	}
+	for pageFile := range pageFiles {
+		pages = append(pages, pageFile)
+	}
+	sort.Strings(hooks)
+	sort.Strings(pages)
+	return
+}
+
+// selectsType reports whether selectionSet, resolved against parent, selects
+// typeName.fieldName, or any field of or returning typeName when fieldName is
+// empty.
+func selectsType(schema *ast.Schema, selectionSet ast.SelectionSet, parent *ast.Definition, fragments map[string]*ast.FragmentDefinition, seen map[string]bool, typeName, fieldName string) bool {
+	if parent == nil {
+		return false
+	}
+
+	for _, sel := range selectionSet {
+		switch s := sel.(type) {
+		case *ast.Field:
+			fieldDef := parent.Fields.ForName(s.Name)
+			if fieldDef == nil {
+				continue
+			}
+			if parent.Name == typeName && (fieldName == "" || fieldName == s.Name) {
+				return true
+			}
+			if fieldName == "" && fieldDef.Type.Name() == typeName {
+				return true
+			}
+			if selectsType(schema, s.SelectionSet, schema.Types[fieldDef.Type.Name()], fragments, seen, typeName, fieldName) {
+				return true
+			}
+		case *ast.InlineFragment:
+			cond := parent
+			if s.TypeCondition != "" {
+				cond = schema.Types[s.TypeCondition]
+			}
+			if selectsType(schema, s.SelectionSet, cond, fragments, seen, typeName, fieldName) {
+				return true
+			}
+		case *ast.FragmentSpread:
+			frag := fragments[s.Name]
+			if frag == nil || seen[s.Name] {
+				continue
+			}
+			seen[s.Name] = true
+			if selectsType(schema, frag.SelectionSet, schema.Types[frag.TypeCondition], fragments, seen, typeName, fieldName) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func reportWhoUses(schemaFilePath, sourcePath, typeName, fieldName string) {
+	// Load schema
+	schema := LoadSchema(schemaFilePath)
+
+	def := schema.Types[typeName]
+	if def == nil {
+		fatalf("type %s not found in schema%s", typeName, didYouMean(typeName, typeNames(schema)))
+	}
+	if fieldName != "" && def.Fields.ForName(fieldName) == nil {
+		fatalf("field %s not found in type %s%s", fieldName, typeName, didYouMean(fieldName, fieldNames(def)))
+	}
+
+	usages := WhoUses(schema, sourcePath, typeName, fieldName)
+	recordResults(usages)
+	for _, u := range usages {
+		fmt.Fprintf(stdout, "%s %s\n", u.Operation, u.Position)
+		for _, hook := range u.Hooks {
+			fmt.Fprintf(stdout, "  hook: %s\n", hook)
+		}
+		for _, page := range u.Pages {
+			fmt.Fprintf(stdout, "  page: %s\n", page)
+		}
+	}
+
+	target := typeName
+	if fieldName != "" {
+		target += "." + fieldName
+	}
+	fmt.Fprintf(stdout, "\n-------\n\n")
+	fmt.Fprintln(stdout, target+":", len(usages), "operations")
+}